@@ -0,0 +1,70 @@
+package tezos
+
+import (
+	"sort"
+	"sync"
+)
+
+// MultiAccountWallet manages the derived accounts of a wallet. Each account has
+// its own signer and source address, and all of them share the http connections
+// of the root wallet. It is safe for concurrent use.
+type MultiAccountWallet struct {
+	root     *Wallet
+	mu       sync.RWMutex
+	accounts map[uint]*Wallet
+}
+
+// NewMultiAccountWallet creates a multi-account wallet from a root wallet
+func NewMultiAccountWallet(root *Wallet) *MultiAccountWallet {
+	return &MultiAccountWallet{
+		root: root,
+		accounts: map[uint]*Wallet{
+			root.accountIndex: root,
+		},
+	}
+}
+
+// Account returns the account of an index. The account is derived once and cached.
+func (m *MultiAccountWallet) Account(index uint) (*Wallet, error) {
+	m.mu.RLock()
+	w, ok := m.accounts[index]
+	m.mu.RUnlock()
+	if ok {
+		return w, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// another goroutine may have derived it in the meantime
+	if w, ok := m.accounts[index]; ok {
+		return w, nil
+	}
+
+	w, err := m.root.DeriveAccount(index)
+	if err != nil {
+		return nil, err
+	}
+	m.accounts[index] = w
+	return w, nil
+}
+
+// Accounts returns all the derived accounts ordered by index
+func (m *MultiAccountWallet) Accounts() []*Wallet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	accounts := make([]*Wallet, 0, len(m.accounts))
+	for _, w := range m.accounts {
+		accounts = append(accounts, w)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].accountIndex < accounts[j].accountIndex
+	})
+	return accounts
+}
+
+// Root returns the root wallet
+func (m *MultiAccountWallet) Root() *Wallet {
+	return m.root
+}
@@ -0,0 +1,71 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveAccountIsolation(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	// bind a rpc client without contacting the node
+	c, err := rpc.NewClient("http://localhost", nil)
+	assert.Nil(t, err)
+	c.Signer = w.signer
+	w.rpcClient = c
+
+	dw, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", dw.Account())
+	assert.EqualValues(t, "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", w.Account())
+
+	assert.True(t, w.RPCClient().Signer == w.Signer())
+	assert.True(t, dw.RPCClient().Signer == dw.Signer())
+	assert.True(t, w.RPCClient() != dw.RPCClient())
+	assert.True(t, w.RPCClient().Client() == dw.RPCClient().Client())
+}
+
+func TestMultiAccountWallet(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	m := NewMultiAccountWallet(w)
+
+	expected := map[uint]string{
+		0: "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd",
+		1: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+		2: "tz1ZRqZEaiwyrMGtZDfxhtMjqijaNy5oFpgK",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(index uint) {
+			defer wg.Done()
+			a, err := m.Account(index)
+			assert.Nil(t, err)
+			assert.EqualValues(t, expected[index], a.Account())
+			_, err = a.SignAuthTransferMessage(expected[0], "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "1", time.Unix(1700000000, 0))
+			assert.Nil(t, err)
+		}(uint(i % 3))
+	}
+	wg.Wait()
+
+	accounts := m.Accounts()
+	assert.Len(t, accounts, 3)
+	for i, a := range accounts {
+		assert.EqualValues(t, expected[uint(i)], a.Account())
+	}
+	assert.True(t, m.Root() == accounts[0])
+
+	a1, _ := m.Account(1)
+	a2, _ := m.Account(1)
+	assert.True(t, a1 == a2)
+}
@@ -0,0 +1,140 @@
+package tezos
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// AuthTransferFormat is the signing format of an authorized transfer
+type AuthTransferFormat int
+
+const (
+	// AuthTransferFormatV1 packs the expiry, contract, recipient and token ID
+	// behind the sign prefix. It is the format of the deployed contracts.
+	AuthTransferFormatV1 AuthTransferFormat = iota
+	// AuthTransferFormatV2 packs a typed Michelson value which also binds the
+	// chain ID, the amount and a per-owner nonce. A contract rebuilds it with
+	// PACK, so a signature can neither be used on another chain nor replayed.
+	AuthTransferFormatV2
+)
+
+// AuthTransferMessage is the authorized transfer of a token which is signed by its owner
+type AuthTransferMessage struct {
+	Format AuthTransferFormat `json:"format"`
+	// ChainID is the chain of the contract, the wallet chain if it is empty. V2 only.
+	ChainID  string    `json:"chain_id,omitempty"`
+	Contract string    `json:"contract"`
+	To       string    `json:"to"`
+	TokenID  string    `json:"token_id"`
+	Expiry   time.Time `json:"expiry"`
+	// Amount is the amount of tokens to transfer. V2 only.
+	Amount int64 `json:"amount,omitempty"`
+	// Nonce is the current transfer nonce of the owner in the contract. V2 only.
+	Nonce int64 `json:"nonce,omitempty"`
+}
+
+// Payload returns the bytes which are signed for the authorized transfer
+func (m AuthTransferMessage) Payload() ([]byte, error) {
+	switch m.Format {
+	case AuthTransferFormatV1:
+		b, err := authTransferMessage(m.To, m.Contract, m.TokenID, m.Expiry)
+		if err != nil {
+			return nil, err
+		}
+		return messagePayload(b), nil
+	case AuthTransferFormatV2:
+		p, err := m.prim()
+		if err != nil {
+			return nil, err
+		}
+		return p.Pack(), nil
+	default:
+		return nil, ErrUnsupportedAuthTransferFormat
+	}
+}
+
+// prim returns the V2 message as the Michelson value
+//
+//	pair chain_id (pair address (pair address (pair nat (pair nat (pair nat timestamp)))))
+//
+// of chain ID, contract, recipient, token ID, amount, nonce and expiry
+func (m AuthTransferMessage) prim() (micheline.Prim, error) {
+	cid, err := tezos.ParseChainIdHash(m.ChainID)
+	if err != nil {
+		return micheline.InvalidPrim, ErrInvalidChainID
+	}
+	contract, err := tezos.ParseAddress(m.Contract)
+	if err != nil || contract.Type() != tezos.AddressTypeContract {
+		return micheline.InvalidPrim, ErrInvalidAddress
+	}
+	to, err := tezos.ParseAddress(m.To)
+	if err != nil {
+		return micheline.InvalidPrim, ErrInvalidAddress
+	}
+	tk, ok := new(big.Int).SetString(m.TokenID, 10)
+	if !ok || tk.Sign() < 0 {
+		return micheline.InvalidPrim, ErrInvalidTokenID
+	}
+	if m.Amount <= 0 {
+		return micheline.InvalidPrim, ErrInvalidAmount
+	}
+	if m.Nonce < 0 {
+		return micheline.InvalidPrim, ErrInvalidNonce
+	}
+
+	return micheline.NewPair(
+		micheline.NewBytes(cid.Bytes()),
+		micheline.NewPair(
+			micheline.NewBytes(contract.EncodePadded()),
+			micheline.NewPair(
+				micheline.NewBytes(to.EncodePadded()),
+				micheline.NewPair(
+					micheline.NewNat(tk),
+					micheline.NewPair(
+						micheline.NewInt64(m.Amount),
+						micheline.NewPair(
+							micheline.NewInt64(m.Nonce),
+							micheline.NewInt64(m.Expiry.Unix()),
+						),
+					),
+				),
+			),
+		),
+	), nil
+}
+
+// SignAuthTransfer signs an authorized transfer with the wallet signer. A V2
+// message is bound to the wallet chain.
+func (w *Wallet) SignAuthTransfer(m AuthTransferMessage) (string, error) {
+	if m.Format == AuthTransferFormatV2 {
+		if m.ChainID == "" {
+			m.ChainID = w.ChainID()
+		}
+		if m.ChainID != w.ChainID() {
+			return "", ErrWrongChainID
+		}
+	}
+
+	b, err := m.Payload()
+	if err != nil {
+		return "", err
+	}
+	sig, err := w.signer.SignBytes(context.Background(), w.address, b)
+	if err != nil {
+		return "", ErrSignFailed
+	}
+	return sig.Generic(), nil
+}
+
+// VerifyAuthTransfer verifies the signature of an authorized transfer with a public key
+func VerifyAuthTransfer(publicKey string, m AuthTransferMessage, signature string) error {
+	b, err := m.Payload()
+	if err != nil {
+		return err
+	}
+	return VerifySignPayload(publicKey, b, signature)
+}
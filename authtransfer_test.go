@@ -0,0 +1,70 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAuthTransfer(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	pk := w.privateKey.Public().String()
+
+	m := AuthTransferMessage{
+		Contract: "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX",
+		To:       "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+		TokenID:  "1",
+		Expiry:   time.Unix(1700000000, 0),
+	}
+
+	// the V1 format is the one of SignAuthTransferMessage
+	sig, err := w.SignAuthTransfer(m)
+	assert.Nil(t, err)
+	legacy, err := w.SignAuthTransferMessage(m.To, m.Contract, m.TokenID, m.Expiry)
+	assert.Nil(t, err)
+	assert.EqualValues(t, legacy, sig)
+	assert.Nil(t, VerifyAuthTransfer(pk, m, sig))
+
+	m.Format = AuthTransferFormatV2
+	m.Amount = 1
+	m.Nonce = 7
+	sig, err = w.SignAuthTransfer(m)
+	assert.Nil(t, err)
+	assert.NotEqualValues(t, legacy, sig)
+
+	m.ChainID = w.ChainID()
+	assert.Nil(t, VerifyAuthTransfer(pk, m, sig))
+
+	b, err := m.Payload()
+	assert.Nil(t, err)
+	// PACK of a pair starting with the chain id bytes of NetXdQprcVkpaWU
+	assert.True(t, strings.HasPrefix(hex.EncodeToString(b), "0507070a000000047a06a770"))
+
+	replay := m
+	replay.Nonce = 8
+	assert.EqualError(t, VerifyAuthTransfer(pk, replay, sig), ErrSignatureMismatch.Error())
+
+	other := m
+	other.ChainID = "NetXnHfVqm9iesp"
+	assert.EqualError(t, VerifyAuthTransfer(pk, other, sig), ErrSignatureMismatch.Error())
+	_, err = w.SignAuthTransfer(other)
+	assert.EqualError(t, err, ErrWrongChainID.Error())
+
+	invalid := m
+	invalid.Amount = 0
+	_, err = w.SignAuthTransfer(invalid)
+	assert.EqualError(t, err, ErrInvalidAmount.Error())
+	invalid = m
+	invalid.Contract = "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"
+	_, err = w.SignAuthTransfer(invalid)
+	assert.EqualError(t, err, ErrInvalidAddress.Error())
+	invalid = m
+	invalid.Format = 3
+	_, err = w.SignAuthTransfer(invalid)
+	assert.EqualError(t, err, ErrUnsupportedAuthTransferFormat.Error())
+}
@@ -0,0 +1,199 @@
+package tezos
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	ed25519hd "github.com/bitmark-inc/go-ed25519-hd"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// HardenedKeyStart is the first index of the hardened child keys
+const HardenedKeyStart = 0x80000000
+
+// hdKey is a hierarchical deterministic master key which derives tezos private keys
+type hdKey interface {
+	derivePrivateKey(path string) (tezos.PrivateKey, error)
+	// wipe zeroes the key material, the key derives nothing afterwards
+	wipe()
+}
+
+// newMasterKey creates a master key of the given key type from a seed
+func newMasterKey(seed []byte, keyType tezos.KeyType) (hdKey, error) {
+	switch keyType {
+	case tezos.KeyTypeEd25519:
+		pk, err := ed25519hd.GetMasterKeyFromSeed(seed)
+		if err != nil {
+			return nil, err
+		}
+		return &ed25519Key{PrivateKey: *pk}, nil
+	case tezos.KeyTypeSecp256k1, tezos.KeyTypeP256:
+		return newExtendedKey(seed, keyType)
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+// ed25519Key is a SLIP-10 ed25519 master key
+type ed25519Key struct {
+	ed25519hd.PrivateKey
+	mu    sync.RWMutex
+	wiped bool
+}
+
+func (k *ed25519Key) derivePrivateKey(path string) (tezos.PrivateKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.wiped {
+		return tezos.PrivateKey{}, ErrKeyWiped
+	}
+
+	dpk, err := k.DeriveChildPrivateKey(path)
+	if err != nil {
+		return tezos.PrivateKey{}, err
+	}
+	return toTzgoPrivateKey(*dpk), nil
+}
+
+func (k *ed25519Key) wipe() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	wipeBytes(k.Key)
+	wipeBytes(k.ChainCode)
+	k.wiped = true
+}
+
+// extendedKey is a BIP32 extended private key on the secp256k1 or P-256 curve.
+// Invalid child keys are handled as described in SLIP-10.
+type extendedKey struct {
+	keyType   tezos.KeyType
+	key       []byte
+	chainCode []byte
+	mu        sync.RWMutex
+	wiped     bool
+}
+
+// newExtendedKey creates a BIP32 master key from a seed
+func newExtendedKey(seed []byte, keyType tezos.KeyType) (*extendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ed25519hd.ErrWrongSeedSize
+	}
+
+	hmacKey := []byte("Bitcoin seed")
+	if keyType == tezos.KeyTypeP256 {
+		hmacKey = []byte("Nist256p1 seed")
+	}
+
+	n := keyType.Curve().Params().N
+	data := seed
+	for {
+		h := hmac.New(sha512.New, hmacKey)
+		h.Write(data)
+		i := h.Sum(nil)
+		k := new(big.Int).SetBytes(i[:32])
+		if k.Sign() != 0 && k.Cmp(n) < 0 {
+			return &extendedKey{
+				keyType:   keyType,
+				key:       i[:32],
+				chainCode: i[32:],
+			}, nil
+		}
+		data = i
+	}
+}
+
+// child derives the child extended key of an index
+func (k *extendedKey) child(index uint32) *extendedKey {
+	curve := k.keyType.Curve()
+	n := curve.Params().N
+
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0}, k.key...)
+	} else {
+		x, y := curve.ScalarBaseMult(k.key)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	for {
+		h := hmac.New(sha512.New, k.chainCode)
+		h.Write(data)
+		i := h.Sum(nil)
+
+		il := new(big.Int).SetBytes(i[:32])
+		if il.Cmp(n) < 0 {
+			ck := il.Add(il, new(big.Int).SetBytes(k.key))
+			ck.Mod(ck, n)
+			if ck.Sign() != 0 {
+				return &extendedKey{
+					keyType:   k.keyType,
+					key:       ck.FillBytes(make([]byte, 32)),
+					chainCode: i[32:],
+				}
+			}
+		}
+		data = binary.BigEndian.AppendUint32(append([]byte{1}, i[32:]...), index)
+	}
+}
+
+func (k *extendedKey) derivePrivateKey(path string) (tezos.PrivateKey, error) {
+	indexes, err := parseDerivePath(path)
+	if err != nil {
+		return tezos.PrivateKey{}, err
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.wiped {
+		return tezos.PrivateKey{}, ErrKeyWiped
+	}
+
+	dk := k
+	for _, i := range indexes {
+		dk = dk.child(i)
+	}
+	return tezos.PrivateKey{
+		Type: k.keyType,
+		// copy the key so it is never shared with the master key
+		Data: append([]byte{}, dk.key...),
+	}, nil
+}
+
+func (k *extendedKey) wipe() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	wipeBytes(k.key)
+	wipeBytes(k.chainCode)
+	k.wiped = true
+}
+
+// parseDerivePath parses a BIP32 derive path like m/44'/1729'/0'/0'
+func parseDerivePath(path string) ([]uint32, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, ed25519hd.ErrWrongDerivePath
+	}
+
+	var indexes []uint32
+	for _, s := range segments[1:] {
+		var offset uint32
+		if strings.HasSuffix(s, "'") {
+			offset = HardenedKeyStart
+			s = strings.TrimSuffix(s, "'")
+		}
+		i, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || i >= HardenedKeyStart {
+			return nil, ed25519hd.ErrWrongDerivePath
+		}
+		indexes = append(indexes, uint32(i)+offset)
+	}
+	return indexes, nil
+}
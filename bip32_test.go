@@ -0,0 +1,100 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	ed25519hd "github.com/bitmark-inc/go-ed25519-hd"
+
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+type extendedKeyVector struct {
+	keyType tezos.KeyType
+	path    string
+	key     string
+}
+
+func TestExtendedKeyDerivation(t *testing.T) {
+	// test vector 1 of BIP32 and SLIP-10
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	for _, v := range testExtendedKeyVectors() {
+		mk, err := newExtendedKey(seed, v.keyType)
+		assert.Nil(t, err)
+		key, err := mk.derivePrivateKey(v.path)
+		assert.Nil(t, err)
+		assert.EqualValues(t, v.key, hex.EncodeToString(key.Data))
+		assert.EqualValues(t, v.keyType, key.Type)
+	}
+}
+
+func TestNewMasterKey(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+
+	prefixes := map[tezos.KeyType]string{
+		tezos.KeyTypeEd25519:   "tz1",
+		tezos.KeyTypeSecp256k1: "tz2",
+		tezos.KeyTypeP256:      "tz3",
+	}
+	for kt, prefix := range prefixes {
+		mk, err := newMasterKey(seed, kt)
+		assert.Nil(t, err)
+		key, err := mk.derivePrivateKey(buildDerivePath(DefaultAccountIndex))
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(key.Address().String(), prefix))
+	}
+
+	mk, err := newMasterKey(seed, tezos.KeyTypeEd25519)
+	assert.Nil(t, err)
+	key, err := mk.derivePrivateKey(buildDerivePath(DefaultAccountIndex))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", key.Address().String())
+
+	_, err = newMasterKey(seed[:8], tezos.KeyTypeSecp256k1)
+	assert.EqualError(t, err, ed25519hd.ErrWrongSeedSize.Error())
+
+	mk, err = newMasterKey(seed, tezos.KeyTypeP256)
+	assert.Nil(t, err)
+	_, err = mk.derivePrivateKey(buildDerivePath(2147483648))
+	assert.EqualError(t, err, ed25519hd.ErrWrongDerivePath.Error())
+
+	_, err = newMasterKey(seed, tezos.KeyTypeBls12_381)
+	assert.EqualError(t, err, ErrUnsupportedKeyType.Error())
+}
+
+func testExtendedKeyVectors() []extendedKeyVector {
+	return []extendedKeyVector{
+		{
+			keyType: tezos.KeyTypeSecp256k1,
+			path:    "m",
+			key:     "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		},
+		{
+			keyType: tezos.KeyTypeSecp256k1,
+			path:    "m/0'",
+			key:     "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		},
+		{
+			keyType: tezos.KeyTypeSecp256k1,
+			path:    "m/0'/1",
+			key:     "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		},
+		{
+			keyType: tezos.KeyTypeP256,
+			path:    "m",
+			key:     "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+		},
+		{
+			keyType: tezos.KeyTypeP256,
+			path:    "m/0'",
+			key:     "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c",
+		},
+		{
+			keyType: tezos.KeyTypeP256,
+			path:    "m/0'/1",
+			key:     "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129",
+		},
+	}
+}
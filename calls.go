@@ -0,0 +1,86 @@
+package tezos
+
+import (
+	"context"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// managerOperationsPass is the validation pass of manager operations in a block
+const managerOperationsPass = 3
+
+// ContractCall represents a contract call of an included operation
+type ContractCall struct {
+	Source      string
+	Destination string
+	Amount      int64
+	Parameters  micheline.Parameters
+}
+
+// GetOperationCalls returns the contract calls of an included operation. The
+// operation is looked up in the block at the given level, or in the recent
+// blocks within the operation TTL when the level is zero.
+func (w *Wallet) GetOperationCalls(hash string, level int64) ([]ContractCall, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
+	h, err := tezos.ParseOpHash(hash)
+	if err != nil {
+		return nil, ErrInvalidOperationHash
+	}
+
+	ctx := context.Background()
+	op, err := w.findOperation(ctx, h, level)
+	if err != nil {
+		return nil, err
+	}
+	return operationCalls(op), nil
+}
+
+// findOperation looks up an operation in the manager operations of a block,
+// or of the recent blocks when the level is zero
+func (w *Wallet) findOperation(ctx context.Context, hash tezos.OpHash, level int64) (*rpc.Operation, error) {
+	from, to := level, level
+	if level == 0 {
+		head, err := w.rpcClient.GetTipHeader(ctx)
+		if err != nil {
+			return nil, err
+		}
+		from, to = head.Level, head.Level-w.params().MaxOperationsTTL
+	}
+
+	for l := from; l >= to && l > 0; l-- {
+		id := rpc.BlockLevel(l)
+		hashes, err := w.rpcClient.GetBlockOperationListHashes(ctx, id, managerOperationsPass)
+		if err != nil {
+			return nil, err
+		}
+		for n, h := range hashes {
+			if h.Equal(hash) {
+				return w.rpcClient.GetBlockOperation(ctx, id, managerOperationsPass, n)
+			}
+		}
+	}
+	return nil, ErrOperationNotFound
+}
+
+// operationCalls returns the contract calls in the contents of an operation
+func operationCalls(op *rpc.Operation) []ContractCall {
+	var calls []ContractCall
+	for _, c := range op.Contents {
+		tx, ok := c.(*rpc.Transaction)
+		if !ok || tx.Parameters == nil || !tx.Destination.IsContract() {
+			continue
+		}
+		calls = append(calls, ContractCall{
+			Source:      tx.Source.String(),
+			Destination: tx.Destination.String(),
+			Amount:      tx.Amount,
+			Parameters:  *tx.Parameters,
+		})
+	}
+	return calls
+}
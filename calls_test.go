@@ -0,0 +1,110 @@
+package tezos
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOperationCalls(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	_, err = w.GetOperationCalls("ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE", 0)
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+
+	source := tezos.MustParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	params := micheline.Parameters{
+		Entrypoint: "burn_editions",
+		Value:      micheline.NewSeq(micheline.NewInt64(77)),
+	}
+	op := rpc.Operation{Contents: rpc.OperationList{
+		&rpc.Transaction{Manager: rpc.Manager{Source: source}, Destination: source, Amount: 1000},
+		&rpc.Transaction{
+			Manager:     rpc.Manager{Source: source},
+			Destination: tezos.MustParseAddress("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"),
+			Parameters:  &params,
+		},
+	}}
+	calls := operationCalls(&op)
+	assert.Len(t, calls, 1)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", calls[0].Source)
+	assert.EqualValues(t, "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", calls[0].Destination)
+	assert.EqualValues(t, params, calls[0].Parameters)
+}
+
+func TestFindOperation(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	hash := tezos.MustParseOpHash("ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE")
+	other := tezos.NewOpHash(make([]byte, 32))
+	head := 102
+	blocks := map[string]string{
+		"/chains/main/blocks/100/operation_hashes/3": fmt.Sprintf(`["%s","%s"]`, other, hash),
+		"/chains/main/blocks/100/operations/3/1": fmt.Sprintf(`{"hash":"%s","contents":[{"kind":"transaction",`+
+			`"source":"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa","fee":"1000","counter":"7","gas_limit":"1500",`+
+			`"storage_limit":"0","amount":"1000","destination":"tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd"}]}`, hash),
+	}
+	var requests []string
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		rw.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/chains/main/blocks/head/header" {
+			_, _ = rw.Write([]byte(fmt.Sprintf(`{"level":%d}`, head)))
+			return
+		}
+		if b, ok := blocks[r.URL.Path]; ok {
+			_, _ = rw.Write([]byte(b))
+			return
+		}
+		_, _ = rw.Write([]byte(fmt.Sprintf(`["%s"]`, other)))
+	}))
+	defer node.Close()
+	c, err := rpc.NewClient(node.URL, nil)
+	assert.Nil(t, err)
+	w.rpcClient = c
+
+	ctx := context.Background()
+	op, err := w.findOperation(ctx, hash, 100)
+	assert.Nil(t, err)
+	assert.EqualValues(t, hash, op.Hash)
+	assert.Len(t, op.Contents, 1)
+	assert.EqualValues(t, []string{
+		"/chains/main/blocks/100/operation_hashes/3",
+		"/chains/main/blocks/100/operations/3/1",
+	}, requests)
+
+	// the recent blocks are scanned from the head
+	requests = nil
+	op, err = w.findOperation(ctx, hash, 0)
+	assert.Nil(t, err)
+	assert.EqualValues(t, hash, op.Hash)
+	assert.EqualValues(t, []string{
+		"/chains/main/blocks/head/header",
+		"/chains/main/blocks/102/operation_hashes/3",
+		"/chains/main/blocks/101/operation_hashes/3",
+		"/chains/main/blocks/100/operation_hashes/3",
+		"/chains/main/blocks/100/operations/3/1",
+	}, requests)
+
+	_, err = w.findOperation(ctx, hash, 101)
+	assert.EqualError(t, err, ErrOperationNotFound.Error())
+
+	// the scan stops at the genesis block
+	head = 3
+	requests = nil
+	_, err = w.findOperation(ctx, hash, 0)
+	assert.EqualError(t, err, ErrOperationNotFound.Error())
+	assert.Len(t, requests, 4)
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"blockwatch.cc/tzgo/micheline"
+)
+
+var (
+	ErrInvalidInput    = errors.New("Input is not a Michelson script, code or interface")
+	ErrUnsupportedType = errors.New("Unsupported Michelson type")
+)
+
+// Config configures the generated package
+type Config struct {
+	// Package is the name of the generated Go package
+	Package string
+	// Name is the name the contract is registered with. It prefixes the
+	// contract type and its factory.
+	Name string
+}
+
+// contractInterface is the parameter and storage type of a contract
+type contractInterface struct {
+	Parameter micheline.Prim `json:"parameter"`
+	Storage   micheline.Prim `json:"storage"`
+}
+
+// parseInput reads a script as returned by the node, the code of a script or
+// a JSON interface with the parameter and storage types. The code is nil for
+// an interface.
+func parseInput(data []byte) (*micheline.Script, *micheline.Code, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil, ErrInvalidInput
+	}
+
+	var code micheline.Code
+	switch data[0] {
+	case '[':
+		if err := json.Unmarshal(data, &code); err != nil {
+			return nil, nil, err
+		}
+	case '{':
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, nil, err
+		}
+		if c, ok := obj["code"]; ok {
+			if err := json.Unmarshal(c, &code); err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+		var ci contractInterface
+		if err := json.Unmarshal(data, &ci); err != nil {
+			return nil, nil, err
+		}
+		if !ci.Parameter.IsValid() || !ci.Storage.IsValid() {
+			return nil, nil, ErrInvalidInput
+		}
+		script := &micheline.Script{Code: micheline.Code{
+			Param:   micheline.NewCode(micheline.K_PARAMETER, ci.Parameter),
+			Storage: micheline.NewCode(micheline.K_STORAGE, ci.Storage),
+		}}
+		return script, nil, nil
+	default:
+		return nil, nil, ErrInvalidInput
+	}
+
+	if !code.Param.IsValid() || !code.Storage.IsValid() || !code.Code.IsValid() {
+		return nil, nil, ErrInvalidInput
+	}
+	return &micheline.Script{Code: code}, &code, nil
+}
+
+// generator writes the Go source of a contract binding
+type generator struct {
+	cfg     Config
+	types   bytes.Buffer
+	names   map[string]bool
+	imports map[string]bool
+}
+
+// Generate emits the Go source of a binding for the contract in data
+func Generate(data []byte, cfg Config) ([]byte, error) {
+	script, code, err := parseInput(data)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		cfg:     cfg,
+		names:   map[string]bool{},
+		imports: map[string]bool{},
+	}
+	for _, n := range []string{"Storage", "DecodeStorage", cfg.Name + "Contract", cfg.Name + "ContractFactory"} {
+		g.names[n] = true
+	}
+
+	eps, err := script.Entrypoints(true)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(eps))
+	for n := range eps {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var body, vars, calls bytes.Buffer
+	for _, n := range names {
+		ep := eps[n]
+		typ := ep.Prim.Clone()
+		typ.Anno = nil
+		annotate(&typ)
+
+		fn := g.unique(goName(n))
+		varName := lowerFirst(fn) + "Type"
+		fmt.Fprintf(&vars, "\t%s = micheline.MustParseType(%s)\n", varName, goLiteral(typ))
+
+		if typ.OpCode == micheline.T_UNIT {
+			g.writeUnitCall(&body, &calls, n, fn, varName)
+			continue
+		}
+		pt, err := g.goType(typ, g.unique(fn+"Param"), false)
+		if err != nil {
+			return nil, fmt.Errorf("entrypoint %s: %w", n, err)
+		}
+		g.writeCall(&body, &calls, n, fn, varName, pt)
+	}
+
+	storage := script.StorageType().Prim.Clone()
+	annotate(&storage)
+	fmt.Fprintf(&vars, "\tstorageType = micheline.MustParseType(%s)\n", goLiteral(storage))
+	st, err := g.goType(storage, "Storage", true)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	if st != "Storage" {
+		fmt.Fprintf(&g.types, "// Storage is the storage of the contract\ntype Storage %s\n\n", st)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by tzbindgen. DO NOT EDIT.\n\npackage %s\n\n", g.cfg.Package)
+	out.WriteString("import (\n")
+	if code != nil {
+		out.WriteString("\t\"bytes\"\n")
+	}
+	out.WriteString("\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n")
+	if g.imports["time"] {
+		out.WriteString("\t\"time\"\n")
+	}
+	out.WriteString("\n\t\"blockwatch.cc/tzgo/contract\"\n\t\"blockwatch.cc/tzgo/micheline\"\n")
+	out.WriteString("\t\"blockwatch.cc/tzgo/rpc\"\n\ttz \"blockwatch.cc/tzgo/tezos\"\n\n")
+	out.WriteString("\ttezos \"github.com/bitmark-inc/account-vault-tezos\"\n)\n\n")
+
+	fmt.Fprintf(&out, "var (\n%s)\n\n", vars.String())
+	if code != nil {
+		c, err := code.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, "// code is the Micheline code of the contract\nconst code = %s\n\n", goLiteral(json.RawMessage(c)))
+	}
+	out.Write(g.types.Bytes())
+	out.Write(body.Bytes())
+	g.writeStorage(&out)
+	g.writeContract(&out, &calls, code != nil)
+
+	return format.Source(out.Bytes())
+}
+
+// goType returns the Go type of a Michelson type. Pairs and ors are declared
+// as struct types with the name. Big maps are given by their id in storage.
+func (g *generator) goType(typ micheline.Prim, name string, storage bool) (string, error) {
+	switch typ.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ,
+		micheline.T_STRING, micheline.T_ADDRESS, micheline.T_CONTRACT, micheline.T_KEY_HASH,
+		micheline.T_KEY, micheline.T_SIGNATURE, micheline.T_CHAIN_ID:
+		return "string", nil
+	case micheline.T_BYTES:
+		return "tz.HexBytes", nil
+	case micheline.T_BOOL:
+		return "bool", nil
+	case micheline.T_TIMESTAMP:
+		g.imports["time"] = true
+		return "time.Time", nil
+	case micheline.T_UNIT:
+		return "interface{}", nil
+	case micheline.T_OPTION:
+		t, err := g.goType(typ.Args[0], name, storage)
+		if err != nil || t == "interface{}" {
+			return t, err
+		}
+		return "*" + t, nil
+	case micheline.T_LIST, micheline.T_SET:
+		// the slice has no named type, so its element takes the name
+		t, err := g.goType(typ.Args[0], name, storage)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + t, nil
+	case micheline.T_BIG_MAP:
+		if storage {
+			return "int64", nil
+		}
+		fallthrough
+	case micheline.T_MAP:
+		t, err := g.goType(typ.Args[1], g.unique(name+"Value"), storage)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + t, nil
+	case micheline.T_PAIR:
+		return name, g.writeStruct(typ, name, storage)
+	case micheline.T_OR:
+		return name, g.writeOr(typ, name, storage)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, typ.OpCode)
+}
+
+// writeStruct declares the struct type of a pair with a field for each member
+func (g *generator) writeStruct(typ micheline.Prim, name string, storage bool) error {
+	var fields bytes.Buffer
+	used := map[string]bool{}
+	for _, m := range pairMembers(typ) {
+		label := m.GetFieldAnnoAny()
+		field := goName(label)
+		for used[field] {
+			field += "_"
+		}
+		used[field] = true
+
+		t, err := g.goType(m, g.unique(name+field), storage)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&fields, "\t%s %s `json:\"%s\"`\n", field, t, label)
+	}
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", name, fields.String())
+	return nil
+}
+
+// writeOr declares the struct type of an or, where only one side is set
+func (g *generator) writeOr(typ micheline.Prim, name string, storage bool) error {
+	l, err := g.goType(typ.Args[0], g.unique(name+sideName(typ.Args[0], "Left")), storage)
+	if err != nil {
+		return err
+	}
+	r, err := g.goType(typ.Args[1], g.unique(name+sideName(typ.Args[1], "Right")), storage)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&g.types, "// %s is either Left or Right\ntype %s struct {\n", name, name)
+	fmt.Fprintf(&g.types, "\tLeft %s `json:\"Left,omitempty\"`\n", pointer(l))
+	fmt.Fprintf(&g.types, "\tRight %s `json:\"Right,omitempty\"`\n}\n\n", pointer(r))
+	return nil
+}
+
+// writeCall writes the arguments type and the call function of an entrypoint
+func (g *generator) writeCall(body, calls *bytes.Buffer, entrypoint, fn, varName, paramType string) {
+	args := g.unique(fn + "Args")
+	fmt.Fprintf(body, `// %[1]s are the arguments of a %[2]s call
+type %[1]s struct {
+	contract.TxArgs
+	Param %[3]s
+}
+
+var _ contract.CallArguments = (*%[1]s)(nil)
+
+// Build validates the param and encodes it as the parameters of the call
+func (a *%[1]s) Build() error {
+	v, err := tezos.EncodeValue(%[4]s, a.Param)
+	if err != nil {
+		return err
+	}
+	a.Params = micheline.Parameters{
+		Entrypoint: %[2]q,
+		Value:      v,
+	}
+	return nil
+}
+
+// Prim returns the Micheline value of the built param
+func (a %[1]s) Prim() micheline.Prim {
+	return a.Params.Value
+}
+
+// %[5]s calls the %[2]s entrypoint
+func %[5]s(w *tezos.Wallet, con *contract.Contract, param %[3]s) (*string, error) {
+	args := %[1]s{Param: param}
+	if err := args.Build(); err != nil {
+		return nil, err
+	}
+	args.WithDestination(con.Address())
+
+	return w.Send(&args)
+}
+
+`, args, entrypoint, paramType, varName, fn)
+
+	fmt.Fprintf(calls, `	case %q:
+		var param %s
+		if err := json.Unmarshal(arguments, &param); err != nil {
+			return nil, err
+		}
+		return %s(wallet, con, param)
+`, entrypoint, paramType, fn)
+}
+
+// writeUnitCall writes the call function of an entrypoint without param
+func (g *generator) writeUnitCall(body, calls *bytes.Buffer, entrypoint, fn, varName string) {
+	fmt.Fprintf(body, `// %[1]s calls the %[2]s entrypoint
+func %[1]s(w *tezos.Wallet, con *contract.Contract) (*string, error) {
+	v, err := tezos.EncodeValue(%[3]s, nil)
+	if err != nil {
+		return nil, err
+	}
+	args := contract.NewTxArgs()
+	args.WithParameters(micheline.Parameters{
+		Entrypoint: %[2]q,
+		Value:      v,
+	})
+	args.WithDestination(con.Address())
+
+	return w.Send(args)
+}
+
+`, fn, entrypoint, varName)
+
+	fmt.Fprintf(calls, "\tcase %q:\n\t\treturn %s(wallet, con)\n", entrypoint, fn)
+}
+
+// writeStorage writes the decoder of the contract storage
+func (g *generator) writeStorage(out *bytes.Buffer) {
+	out.WriteString(`// DecodeStorage decodes the storage of the contract
+func DecodeStorage(p micheline.Prim) (*Storage, error) {
+	var s Storage
+	if err := tezos.DecodeValue(storageType, p, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+`)
+}
+
+// writeContract writes the Contract implementation and its registration
+func (g *generator) writeContract(out, calls *bytes.Buffer, hasCode bool) {
+	name := g.cfg.Name
+	fmt.Fprintf(out, `type %[1]sContract struct {
+	contractAddress string
+}
+
+func %[1]sContractFactory(contractAddress string) tezos.Contract {
+	return &%[1]sContract{
+		contractAddress: contractAddress,
+	}
+}
+
+`, name)
+
+	if hasCode {
+		fmt.Fprintf(out, `// Deploy deploys the smart contract to tezos blockchain. The arguments are the
+// initial storage as accepted by tezos.EncodeValue.
+func (c *%[1]sContract) Deploy(wallet *tezos.Wallet, arguments json.RawMessage) (string, string, error) {
+	var script micheline.Script
+	if err := json.Unmarshal([]byte(code), &script.Code); err != nil {
+		return "", "", err
+	}
+	// numbers are kept as text so big nats are not rounded
+	var storage interface{}
+	d := json.NewDecoder(bytes.NewReader(arguments))
+	d.UseNumber()
+	if err := d.Decode(&storage); err != nil {
+		return "", "", err
+	}
+	s, err := tezos.EncodeValue(storageType, storage)
+	if err != nil {
+		return "", "", err
+	}
+	script.Storage = s
+
+	return wallet.OriginateScript(script)
+}
+
+`, name)
+	} else {
+		fmt.Fprintf(out, `// Deploy is not supported since the binding is generated without the contract code
+func (c *%[1]sContract) Deploy(wallet *tezos.Wallet, arguments json.RawMessage) (string, string, error) {
+	return "", "", fmt.Errorf("unsupported deploy")
+}
+
+`, name)
+	}
+
+	fmt.Fprintf(out, `// Call is the entry function for account vault to interact with a smart contract.
+func (c *%[1]sContract) Call(wallet *tezos.Wallet, method string, arguments json.RawMessage) (*string, error) {
+	ca, err := tz.ParseAddress(c.contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	// construct a new contract
+	con := contract.NewContract(ca, wallet.RPCClient())
+
+	switch method {
+%[2]s	default:
+		return nil, fmt.Errorf("unsupported method")
+	}
+}
+
+// Storage loads and decodes the current storage of the contract
+func (c *%[1]sContract) Storage(wallet *tezos.Wallet) (*Storage, error) {
+	ca, err := tz.ParseAddress(c.contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	p, err := wallet.RPCClient().GetContractStorage(context.Background(), ca, rpc.Head)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeStorage(p)
+}
+
+func init() {
+	tezos.RegisterContract(%[3]q, %[1]sContractFactory)
+}
+`, name, calls.String(), name)
+}
+
+// unique returns an identifier which has not been used in the package
+func (g *generator) unique(name string) string {
+	n := name
+	for i := 2; g.names[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[n] = true
+	return n
+}
+
+// annotate adds field annotations to the pair members which have none, so
+// that every member has a name in Go and JSON
+func annotate(typ *micheline.Prim) {
+	n := 0
+	var walk func(p *micheline.Prim)
+	walk = func(p *micheline.Prim) {
+		for i := range p.Args {
+			walk(&p.Args[i])
+		}
+		if p.OpCode != micheline.T_PAIR {
+			return
+		}
+		for i := range p.Args {
+			a := &p.Args[i]
+			if a.GetFieldAnnoAny() == "" && a.OpCode != micheline.T_PAIR {
+				a.Anno = append(a.Anno, fmt.Sprintf("%%arg%d", n))
+				n++
+			}
+		}
+	}
+	walk(typ)
+}
+
+// pairMembers returns the members of a pair. Members of nested pairs without
+// annotation belong to the same pair.
+func pairMembers(typ micheline.Prim) []micheline.Prim {
+	var members []micheline.Prim
+	for _, a := range typ.Args {
+		if a.OpCode == micheline.T_PAIR && a.GetFieldAnnoAny() == "" {
+			members = append(members, pairMembers(a)...)
+			continue
+		}
+		members = append(members, a)
+	}
+	return members
+}
+
+// sideName returns the name of a side of an or from its annotation
+func sideName(typ micheline.Prim, side string) string {
+	if label := typ.GetFieldAnnoAny(); label != "" {
+		return goName(label)
+	}
+	return side
+}
+
+// goName converts a Michelson name like token_id into an exported Go name like TokenID
+func goName(s string) string {
+	initialisms := map[string]string{"id": "ID", "url": "URL", "uri": "URI", "ipfs": "IPFS", "json": "JSON"}
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if i, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(i)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "F" + name
+	}
+	return name
+}
+
+func lowerFirst(s string) string {
+	if strings.ToUpper(s) == s {
+		return strings.ToLower(s)
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func pointer(t string) string {
+	if t == "interface{}" || strings.HasPrefix(t, "*") {
+		return t
+	}
+	return "*" + t
+}
+
+// goLiteral returns a Go string literal of a JSON value
+func goLiteral(v interface{}) string {
+	b, _ := json.Marshal(v)
+	if bytes.ContainsRune(b, '`') {
+		return fmt.Sprintf("%q", b)
+	}
+	return "`" + string(b) + "`"
+}
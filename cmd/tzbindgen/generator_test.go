@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("testdata/script.json")
+	assert.Nil(t, err)
+
+	src, err := Generate(data, Config{Package: "sample", Name: "Sample"})
+	assert.Nil(t, err)
+	s := string(src)
+	assert.Contains(t, s, "package sample")
+	assert.Contains(t, s, "type TransferParam struct {")
+	assert.Contains(t, s, "\tTxs  []TransferParamTxs `json:\"txs\"`")
+	assert.Contains(t, s, "\tTokenID string `json:\"token_id\"`")
+	assert.Contains(t, s, "\tExpiry   *time.Time             `json:\"expiry\"`")
+	assert.Contains(t, s, "\tLeft  *UpdateOperatorsParamAddOperator    `json:\"Left,omitempty\"`")
+	// members without annotation are named by position
+	assert.Contains(t, s, "\tArg0 string `json:\"arg0\"`")
+	// big maps in storage are given by their id
+	assert.Contains(t, s, "\tLedger            int64       `json:\"ledger\"`")
+	assert.Contains(t, s, "func Transfer(w *tezos.Wallet, con *contract.Contract, param []TransferParam) (*string, error) {")
+	assert.Contains(t, s, "func Ping(w *tezos.Wallet, con *contract.Contract) (*string, error) {")
+	assert.Contains(t, s, "const code = ")
+	assert.Contains(t, s, "tezos.RegisterContract(\"Sample\", SampleContractFactory)")
+	assert.Contains(t, s, "d.UseNumber()")
+	assertCompiles(t, src)
+
+	// an interface has no code to deploy
+	src, err = Generate([]byte(`{"parameter":{"prim":"nat","annots":["%set"]},"storage":{"prim":"nat"}}`), Config{Package: "counter", Name: "Counter"})
+	assert.Nil(t, err)
+	s = string(src)
+	assert.Contains(t, s, "func Set(w *tezos.Wallet, con *contract.Contract, param string) (*string, error) {")
+	assert.Contains(t, s, "type Storage string")
+	assert.Contains(t, s, "unsupported deploy")
+	assert.NotContains(t, s, "const code")
+	assertCompiles(t, src)
+
+	_, err = Generate([]byte(`"nat"`), Config{Package: "bad", Name: "Bad"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = Generate([]byte(`{"parameter":{"prim":"lambda","args":[{"prim":"unit"},{"prim":"unit"}]},"storage":{"prim":"unit"}}`), Config{Package: "bad", Name: "Bad"})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+// assertCompiles builds a generated binding as a package of this module
+func assertCompiles(t *testing.T, src []byte) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command is not available")
+	}
+	// a directory starting with _ is left out of ./... of the module
+	dir, err := os.MkdirTemp(".", "_binding")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "binding.go"), src, 0644))
+
+	out, err := exec.Command("go", "build", "./"+dir).CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
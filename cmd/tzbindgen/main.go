@@ -0,0 +1,49 @@
+// Command tzbindgen generates a typed Go binding of a Tezos contract from its
+// Michelson script. It is meant to be run through go generate, e.g.
+//
+//	//go:generate go run github.com/bitmark-inc/account-vault-tezos/cmd/tzbindgen -script script.json -package feralfilev3 -name FeralfileExhibitionV3 -o binding.go
+//
+// The script is the JSON returned by the node for a contract script, the
+// Micheline JSON of its code, or a JSON interface with the parameter and the
+// storage type like {"parameter": ..., "storage": ...}.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	script := flag.String("script", "", "path of the contract script or interface JSON")
+	pkg := flag.String("package", "", "name of the generated package")
+	name := flag.String("name", "", "name the contract is registered with")
+	output := flag.String("o", "", "path of the generated file, stdout when empty")
+	flag.Parse()
+
+	if *script == "" || *pkg == "" || *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*script, *output, Config{Package: *pkg, Name: *name}); err != nil {
+		fmt.Fprintln(os.Stderr, "tzbindgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(script, output string, cfg Config) error {
+	data, err := os.ReadFile(script)
+	if err != nil {
+		return err
+	}
+	src, err := Generate(data, cfg)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
@@ -11,6 +11,34 @@ type Contract interface {
 	Call(wallet *Wallet, method string, arguments json.RawMessage) (tx *string, err error)
 }
 
+// ResultContract is a Contract which returns the result of a call with its costs
+type ResultContract interface {
+	Contract
+	CallWithResult(wallet *Wallet, method string, arguments json.RawMessage) (*CallResult, error)
+}
+
+// CallContract calls a contract method and returns the result of the sent operation.
+// The result of a contract which is not a ResultContract is recorded from the wallet.
+func CallContract(c Contract, wallet *Wallet, method string, arguments json.RawMessage) (*CallResult, error) {
+	if rc, ok := c.(ResultContract); ok {
+		return rc.CallWithResult(wallet, method, arguments)
+	}
+
+	w, recorder := wallet.recording()
+	hash, err := c.Call(w, method, arguments)
+	if err != nil {
+		return nil, err
+	}
+	if hash == nil {
+		return nil, ErrCallResultNotFound
+	}
+	res, ok := recorder.result(*hash)
+	if !ok {
+		return nil, ErrCallResultNotFound
+	}
+	return res, nil
+}
+
 // ContractFactory is a function that takes an address and return a Contract instance
 type ContractFactory func(string) Contract
 
@@ -1,6 +1,7 @@
 package feralfilev1
 
 import (
+	_ "embed"
 	"encoding/json"
 	"fmt"
 
@@ -11,6 +12,11 @@ import (
 	fff "github.com/bitmark-inc/account-vault-tezos/contracts/feralfile-feature"
 )
 
+// exhibitionCode is the compiled Micheline of the V1 exhibition contract in its JSON form
+//
+//go:embed exhibition_code.json
+var exhibitionCode []byte
+
 type FeralfileExhibitionV1Contract struct {
 	contractAddress string
 }
@@ -21,10 +27,17 @@ func FeralfileExhibitionV1ContractFactory(contractAddress string) tezos.Contract
 	}
 }
 
-// FIXME: TODO
 // Deploy deploys the smart contract to tezos blockchain
 func (c *FeralfileExhibitionV1Contract) Deploy(wallet *tezos.Wallet, arguments json.RawMessage) (string, string, error) {
-	return "", "", nil
+	code, err := fff.ParseContractCode(exhibitionCode)
+	if err != nil {
+		return "", "", err
+	}
+	var param fff.DeployParam
+	if err := json.Unmarshal(arguments, &param); err != nil {
+		return "", "", err
+	}
+	return fff.Deploy(wallet, code, param)
 }
 
 // Call is the entry function for account vault to interact with a smart contract.
@@ -16,6 +16,9 @@ type AuthTransferParam struct {
 	PK     string            `json:"pk"`
 	Expiry time.Time         `json:"expiry"`
 	Txs    []AuthTransaction `json:"txs"`
+	// Format is the signing format of the transactions, the V2 format requires a contract
+	// which checks the chain ID, amount and nonce
+	Format tezos.AuthTransferFormat `json:"format,omitempty"`
 }
 
 func (a AuthTransferParam) Build() (*authTransferParam, error) {
@@ -29,27 +32,40 @@ func (a AuthTransferParam) Build() (*authTransferParam, error) {
 	}
 	var txs []authTransaction
 	for _, tx := range a.Txs {
-		x, err := tx.Build()
+		x, err := tx.build(a.Format)
 		if err != nil {
 			return nil, err
 		}
 		txs = append(txs, *x)
 	}
-	return &authTransferParam{
+	p := &authTransferParam{
 		From:   from_,
 		PK:     pk_,
 		Expiry: big.NewInt(a.Expiry.Unix()),
 		Txs:    txs,
-	}, nil
+		Format: a.Format,
+	}
+	if _, err := tezos.MarshalMichelson(p); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 type AuthTransaction struct {
 	To        string `json:"to"`
 	Signature string `json:"signature"`
 	TokenID   string `json:"token_id"`
+	// Amount is the amount of tokens, one if it is empty. V2 only.
+	Amount string `json:"amount,omitempty"`
+	// Nonce is the transfer nonce of the owner which is signed. V2 only.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 func (a AuthTransaction) Build() (*authTransaction, error) {
+	return a.build(tezos.AuthTransferFormatV1)
+}
+
+func (a AuthTransaction) build(format tezos.AuthTransferFormat) (*authTransaction, error) {
 	sig_, err := tz.ParseSignature(a.Signature)
 	if err != nil {
 		return nil, ErrInvalidSignature
@@ -62,12 +78,32 @@ func (a AuthTransaction) Build() (*authTransaction, error) {
 	if err != nil {
 		return nil, ErrInvalidAddress
 	}
-	return &authTransaction{
+	tx := &authTransaction{
 		Signature: sig_,
 		TokenID:   tk,
 		To:        to_,
 		Amount:    big.NewInt(1),
-	}, nil
+	}
+
+	switch format {
+	case tezos.AuthTransferFormatV1:
+	case tezos.AuthTransferFormatV2:
+		if a.Amount != "" {
+			amount, ok := new(big.Int).SetString(a.Amount, 10)
+			if !ok || amount.Sign() <= 0 {
+				return nil, ErrInvalidAmount
+			}
+			tx.Amount = amount
+		}
+		nonce, ok := new(big.Int).SetString(a.Nonce, 10)
+		if !ok || nonce.Sign() < 0 {
+			return nil, ErrInvalidNonce
+		}
+		tx.Nonce = nonce
+	default:
+		return nil, tezos.ErrUnsupportedAuthTransferFormat
+	}
+	return tx, nil
 }
 
 type authTransferParam struct {
@@ -75,13 +111,17 @@ type authTransferParam struct {
 	PK     tz.Key
 	Expiry *big.Int
 	Txs    []authTransaction
+	Format tezos.AuthTransferFormat `michelson:"-"`
 }
 
+// authTransaction is a transaction in the V1 format, or with the signed nonce
+// in the V2 format. The signature is given to the contract as bytes.
 type authTransaction struct {
 	To        tz.Address
-	Signature tz.Signature
-	Amount    *big.Int
 	TokenID   *big.Int
+	Amount    *big.Int     `michelson:"nat"`
+	Nonce     *big.Int     `michelson:"nat,omitempty"`
+	Signature tz.Signature `michelson:"bytes"`
 }
 
 type authTransferArgs struct {
@@ -91,43 +131,8 @@ type authTransferArgs struct {
 
 var _ contract.CallArguments = (*authTransferArgs)(nil)
 
-func (p authTransferParam) Prim() micheline.Prim {
-	rs := micheline.NewSeq()
-	for _, v := range p.Txs {
-		rs.Args = append(rs.Args,
-			micheline.NewPair(
-				micheline.NewBytes(v.To.EncodePadded()),
-				micheline.NewPair(
-					micheline.NewBig(v.TokenID),
-					micheline.NewPair(
-						micheline.NewNat(v.Amount),
-						micheline.NewBytes(v.Signature.Bytes()),
-					),
-				),
-			),
-		)
-	}
-	return rs
-}
-
-func (p authTransferArgs) Prim() micheline.Prim {
-	rs := micheline.NewSeq()
-	for i, v := range p.Transfers {
-		rs.Args = append(rs.Args,
-			micheline.NewPair(
-				micheline.NewBytes(v.From.EncodePadded()),
-				micheline.NewPair(
-					micheline.NewBytes(v.PK.Bytes()),
-					micheline.NewPair(
-						micheline.NewBig(v.Expiry),
-						micheline.NewSeq(),
-					),
-				),
-			),
-		)
-		rs.Args[i].Args[1].Args[1].Args[1] = v.Prim()
-	}
-	return rs
+func (p authTransferArgs) Prim() (micheline.Prim, error) {
+	return tezos.MarshalMichelson(p.Transfers)
 }
 
 // authTransfer call the authorized transfer entrypoint define in FeralFile contract
@@ -145,9 +150,13 @@ func AuthTransfer(w *tezos.Wallet, con *contract.Contract, aps []AuthTransferPar
 		Transfers: aps_,
 	}
 
+	value, err := args.Prim()
+	if err != nil {
+		return nil, err
+	}
 	args.Params = micheline.Parameters{
 		Entrypoint: "authorized_transfer",
-		Value:      args.Prim(),
+		Value:      value,
 	}
 	args.WithDestination(con.Address())
 
@@ -0,0 +1,261 @@
+package feralfilefeature
+
+import (
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"blockwatch.cc/tzgo/contract"
+	"blockwatch.cc/tzgo/micheline"
+	tz "blockwatch.cc/tzgo/tezos"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+// mintEditionValue is the Michelson value of a mint_editions item
+type mintEditionValue struct {
+	Owner  tz.Address
+	Tokens []struct {
+		Metadata  map[string][]byte
+		ArtworkID []byte
+		Edition   *big.Int `michelson:"nat"`
+	}
+}
+
+// registerArtworkValue is the Michelson value of a register_artworks item
+type registerArtworkValue struct {
+	Title          string
+	ArtistName     string
+	Fingerprint    []byte
+	MaxEdition     *big.Int
+	AEAmount       *big.Int
+	PPAmount       *big.Int
+	RoyaltyAddress tz.Address
+}
+
+// updateEditionMetadataValue is the Michelson value of an update_edition_metadata item
+type updateEditionMetadataValue struct {
+	TokenID  *big.Int
+	Metadata map[string][]byte
+}
+
+// authTransferInput is an authorized_transfer item with the transactions
+// decoded by their format
+type authTransferInput struct {
+	From   tz.Address
+	PK     tz.Key
+	Expiry *big.Int
+	Txs    []micheline.Prim
+}
+
+// GetContractCalls returns the parameters of the calls to the contract in an
+// included operation. The operation is looked up like in Wallet.GetOperationCalls.
+func GetContractCalls(w *tezos.Wallet, con *contract.Contract, hash string, level int64) ([]micheline.Parameters, error) {
+	calls, err := w.GetOperationCalls(hash, level)
+	if err != nil {
+		return nil, err
+	}
+	var params []micheline.Parameters
+	for _, c := range calls {
+		if c.Destination == con.Address().String() {
+			params = append(params, c.Parameters)
+		}
+	}
+	return params, nil
+}
+
+// DecodeCall decodes the parameters of a call into the param type of its
+// entrypoint, e.g. []MintEditionParam for mint_editions
+func DecodeCall(p micheline.Parameters) (interface{}, error) {
+	switch p.Entrypoint {
+	case "mint_editions":
+		return DecodeMintEditions(p)
+	case "register_artworks":
+		return DecodeRegisterArtworks(p)
+	case "authorized_transfer":
+		return DecodeAuthTransfer(p)
+	case "burn_editions":
+		return DecodeBurnEditions(p)
+	case "update_edition_metadata":
+		return DecodeUpdateEditionMetadata(p)
+	}
+	return nil, ErrUnexpectedEntrypoint
+}
+
+// DecodeMintEditions decodes the parameters of a mint_editions call
+func DecodeMintEditions(p micheline.Parameters) ([]MintEditionParam, error) {
+	var values []mintEditionValue
+	if err := decodeParameters(p, "mint_editions", &values); err != nil {
+		return nil, err
+	}
+
+	mes := []MintEditionParam{}
+	for _, v := range values {
+		me := MintEditionParam{
+			Owner:  v.Owner.String(),
+			Tokens: []MintEditionToken{},
+		}
+		for _, tk := range v.Tokens {
+			if !tk.Edition.IsInt64() {
+				return nil, ErrInvalidParameters
+			}
+			me.Tokens = append(me.Tokens, MintEditionToken{
+				IPFSLink:  string(tk.Metadata[""]),
+				ArtworkID: hex.EncodeToString(tk.ArtworkID),
+				Edition:   tk.Edition.Int64(),
+			})
+		}
+		mes = append(mes, me)
+	}
+	return mes, nil
+}
+
+// DecodeRegisterArtworks decodes the parameters of a register_artworks call
+func DecodeRegisterArtworks(p micheline.Parameters) ([]RegisterArtworkParam, error) {
+	var values []registerArtworkValue
+	if err := decodeParameters(p, "register_artworks", &values); err != nil {
+		return nil, err
+	}
+
+	ras := []RegisterArtworkParam{}
+	for _, v := range values {
+		fingerprint, err := getUnpackedFingerprint(v.Fingerprint)
+		if err != nil {
+			return nil, ErrInvalidParameters
+		}
+		if !v.MaxEdition.IsInt64() || !v.AEAmount.IsInt64() || !v.PPAmount.IsInt64() {
+			return nil, ErrInvalidParameters
+		}
+		ras = append(ras, RegisterArtworkParam{
+			ArtistName:     v.ArtistName,
+			Fingerprint:    fingerprint,
+			Title:          v.Title,
+			MaxEdition:     v.MaxEdition.Int64(),
+			AEAmount:       v.AEAmount.Int64(),
+			PPAmount:       v.PPAmount.Int64(),
+			RoyaltyAddress: v.RoyaltyAddress.String(),
+		})
+	}
+	return ras, nil
+}
+
+// DecodeAuthTransfer decodes the parameters of an authorized_transfer call. The
+// format of each transfer is detected from its transactions.
+func DecodeAuthTransfer(p micheline.Parameters) ([]AuthTransferParam, error) {
+	var values []authTransferInput
+	if err := decodeParameters(p, "authorized_transfer", &values); err != nil {
+		return nil, err
+	}
+
+	aps := []AuthTransferParam{}
+	for _, v := range values {
+		if !v.Expiry.IsInt64() {
+			return nil, ErrInvalidParameters
+		}
+		ap := AuthTransferParam{
+			From:   v.From.String(),
+			PK:     v.PK.String(),
+			Expiry: time.Unix(v.Expiry.Int64(), 0),
+			Txs:    []AuthTransaction{},
+			Format: tezos.AuthTransferFormatV1,
+		}
+		for i, prim := range v.Txs {
+			tx, format, err := decodeAuthTransaction(prim)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 && format != ap.Format {
+				return nil, ErrInvalidParameters
+			}
+			ap.Format = format
+			ap.Txs = append(ap.Txs, *tx)
+		}
+		aps = append(aps, ap)
+	}
+	return aps, nil
+}
+
+// decodeAuthTransaction decodes a transaction of the V2 format, or of the V1
+// format when it has no nonce
+func decodeAuthTransaction(p micheline.Prim) (*AuthTransaction, tezos.AuthTransferFormat, error) {
+	var v authTransaction
+	if err := tezos.UnmarshalMichelson(p, &v); err != nil {
+		return nil, 0, err
+	}
+	tx := &AuthTransaction{
+		To:        v.To.String(),
+		Signature: v.Signature.String(),
+		TokenID:   v.TokenID.String(),
+	}
+	if v.Nonce == nil {
+		return tx, tezos.AuthTransferFormatV1, nil
+	}
+	tx.Amount = v.Amount.String()
+	tx.Nonce = v.Nonce.String()
+	return tx, tezos.AuthTransferFormatV2, nil
+}
+
+// DecodeBurnEditions decodes the parameters of a burn_editions call
+func DecodeBurnEditions(p micheline.Parameters) ([]BurnEditionsParam, error) {
+	var values []*big.Int
+	if err := decodeParameters(p, "burn_editions", &values); err != nil {
+		return nil, err
+	}
+
+	bes := []BurnEditionsParam{}
+	for _, v := range values {
+		bes = append(bes, BurnEditionsParam(v.String()))
+	}
+	return bes, nil
+}
+
+// DecodeUpdateEditionMetadata decodes the parameters of an update_edition_metadata call
+func DecodeUpdateEditionMetadata(p micheline.Parameters) ([]UpdateEditionMetadataParam, error) {
+	var values []updateEditionMetadataValue
+	if err := decodeParameters(p, "update_edition_metadata", &values); err != nil {
+		return nil, err
+	}
+
+	uem := []UpdateEditionMetadataParam{}
+	for _, v := range values {
+		uem = append(uem, UpdateEditionMetadataParam{
+			TokenID:  v.TokenID.String(),
+			IPFSLink: string(v.Metadata[""]),
+		})
+	}
+	return uem, nil
+}
+
+// decodeParameters checks the entrypoint of the parameters and unmarshals their value
+func decodeParameters(p micheline.Parameters, entrypoint string, v interface{}) error {
+	if p.Entrypoint != entrypoint {
+		return ErrUnexpectedEntrypoint
+	}
+	return tezos.UnmarshalMichelson(p.Value, v)
+}
+
+// getUnpackedFingerprint returns the fingerprint of a packed fingerprint, see
+// getPackedFingerprint
+func getUnpackedFingerprint(packed []byte) (string, error) {
+	stringTy, err := abi.NewType("string", "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	args := abi.Arguments{
+		{
+			Type: stringTy,
+		},
+	}
+
+	values, err := args.Unpack(packed)
+	if err != nil {
+		return "", err
+	}
+	fingerprint, ok := values[0].(string)
+	if !ok {
+		return "", ErrInvalidParameters
+	}
+	return fingerprint, nil
+}
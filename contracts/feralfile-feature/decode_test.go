@@ -0,0 +1,181 @@
+package feralfilefeature
+
+import (
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	tz "blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+func testSignature() string {
+	sig := tz.Signature{Type: tz.SignatureTypeEd25519, Data: make([]byte, 64)}
+	sig.Data[0] = 1
+	return sig.String()
+}
+
+func TestDecodeCall(t *testing.T) {
+	expiry := time.Unix(1700000000, 0)
+	tests := []struct {
+		name       string
+		entrypoint string
+		value      func() (micheline.Prim, error)
+		params     interface{}
+	}{
+		{
+			name:       "mint_editions",
+			entrypoint: "mint_editions",
+			params: []MintEditionParam{{
+				Owner: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+				Tokens: []MintEditionToken{
+					{IPFSLink: "ipfs://QmA", ArtworkID: "0a0b", Edition: 1},
+					{IPFSLink: "ipfs://QmB", ArtworkID: "0a0b", Edition: 2},
+				},
+			}},
+		},
+		{
+			name:       "register_artworks",
+			entrypoint: "register_artworks",
+			params: []RegisterArtworkParam{{
+				ArtistName:     "artist",
+				Fingerprint:    "fingerprint",
+				Title:          "title",
+				MaxEdition:     10,
+				AEAmount:       1,
+				PPAmount:       2,
+				RoyaltyAddress: "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd",
+			}},
+		},
+		{
+			name:       "authorized_transfer v1",
+			entrypoint: "authorized_transfer",
+			params: []AuthTransferParam{{
+				From:   "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd",
+				PK:     "edpkuAJhbFLfJ4zWbQQWTZNGDg7hrcG1m1CBSWVB3iDHChjuzeaZB6",
+				Expiry: expiry,
+				Txs: []AuthTransaction{
+					{To: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", Signature: testSignature(), TokenID: "5"},
+				},
+				Format: tezos.AuthTransferFormatV1,
+			}},
+		},
+		{
+			name:       "authorized_transfer v2",
+			entrypoint: "authorized_transfer",
+			params: []AuthTransferParam{{
+				From:   "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd",
+				PK:     "edpkuAJhbFLfJ4zWbQQWTZNGDg7hrcG1m1CBSWVB3iDHChjuzeaZB6",
+				Expiry: expiry,
+				Txs: []AuthTransaction{
+					{To: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", Signature: testSignature(), TokenID: "5", Amount: "2", Nonce: "0"},
+					{To: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", Signature: testSignature(), TokenID: "6", Amount: "1", Nonce: "1"},
+				},
+				Format: tezos.AuthTransferFormatV2,
+			}},
+		},
+		{
+			name:       "burn_editions",
+			entrypoint: "burn_editions",
+			params:     []BurnEditionsParam{"1", "18446744073709551617"},
+		},
+		{
+			name:       "update_edition_metadata",
+			entrypoint: "update_edition_metadata",
+			params: []UpdateEditionMetadataParam{
+				{TokenID: "7", IPFSLink: "ipfs://QmC"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := encodeCall(tt.params)
+			assert.Nil(t, err)
+
+			// the value is read back from its binary encoding like from the chain
+			b, err := value.MarshalBinary()
+			assert.Nil(t, err)
+			var p micheline.Parameters
+			p.Entrypoint = tt.entrypoint
+			assert.Nil(t, p.Value.UnmarshalBinary(b))
+
+			params, err := DecodeCall(p)
+			assert.Nil(t, err)
+			assert.EqualValues(t, tt.params, params)
+		})
+	}
+}
+
+func TestDecodeCallErrors(t *testing.T) {
+	_, err := DecodeCall(micheline.Parameters{Entrypoint: "transfer", Value: micheline.NewSeq()})
+	assert.Equal(t, ErrUnexpectedEntrypoint, err)
+
+	_, err = DecodeMintEditions(micheline.Parameters{Entrypoint: "burn_editions", Value: micheline.NewSeq()})
+	assert.Equal(t, ErrUnexpectedEntrypoint, err)
+
+	_, err = DecodeBurnEditions(micheline.Parameters{
+		Entrypoint: "burn_editions",
+		Value:      micheline.NewSeq(micheline.NewString("1")),
+	})
+	assert.ErrorIs(t, err, tezos.ErrInvalidMichelsonValue)
+}
+
+// encodeCall returns the parameter value of a call like it is sent
+func encodeCall(params interface{}) (micheline.Prim, error) {
+	switch ps := params.(type) {
+	case []MintEditionParam:
+		var args mintEditionArgs
+		for _, p := range ps {
+			v, err := p.Build()
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			args.Editions = append(args.Editions, *v)
+		}
+		return args.Prim(), nil
+	case []RegisterArtworkParam:
+		var args registerArtworkArgs
+		for _, p := range ps {
+			v, err := p.Build()
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			args.Artworks = append(args.Artworks, *v)
+		}
+		return args.Prim(), nil
+	case []AuthTransferParam:
+		var args authTransferArgs
+		for _, p := range ps {
+			v, err := p.Build()
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			args.Transfers = append(args.Transfers, *v)
+		}
+		return args.Prim()
+	case []BurnEditionsParam:
+		var args burnEditionsArgs
+		for _, p := range ps {
+			v, err := p.Build()
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			args.burnEditions = append(args.burnEditions, *v)
+		}
+		return args.Prim(), nil
+	case []UpdateEditionMetadataParam:
+		var args updateEditionMetadataArgs
+		for _, p := range ps {
+			v, err := p.Build()
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			args.updateEditions = append(args.updateEditions, *v)
+		}
+		return args.Prim(), nil
+	}
+	return micheline.InvalidPrim, ErrUnexpectedEntrypoint
+}
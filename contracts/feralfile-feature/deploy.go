@@ -0,0 +1,117 @@
+package feralfilefeature
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"blockwatch.cc/tzgo/micheline"
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+// DeployParam is the argument for deploying an exhibition contract. The named
+// fields fill the storage fields with the same annotation, and Storage provides
+// any other field. Map, set and list fields which are not given start empty.
+type DeployParam struct {
+	Admin             string                 `json:"admin"`
+	Trustees          []string               `json:"trustees"`
+	ExhibitionTitle   string                 `json:"exhibition_title"`
+	TokenMetadataBase string                 `json:"token_metadata_base"`
+	Storage           map[string]interface{} `json:"storage,omitempty"`
+}
+
+// Build returns the script of the contract code with the initial storage built
+// against the storage type of the code
+func (d DeployParam) Build(code micheline.Code) (*micheline.Script, error) {
+	if !code.Param.IsValid() || !code.Storage.IsValid() || !code.Code.IsValid() {
+		return nil, ErrInvalidContractCode
+	}
+	if a, err := tz.ParseAddress(d.Admin); err != nil || !a.IsValid() {
+		return nil, ErrInvalidAddress
+	}
+	trustees := make([]interface{}, 0, len(d.Trustees))
+	for _, t := range d.Trustees {
+		if a, err := tz.ParseAddress(t); err != nil || !a.IsValid() {
+			return nil, ErrInvalidAddress
+		}
+		trustees = append(trustees, t)
+	}
+
+	script := &micheline.Script{Code: code}
+	typ := script.StorageType()
+	fields := map[string]micheline.Prim{}
+	storageFields(typ.Prim, fields)
+
+	values := map[string]interface{}{}
+	for k, v := range d.Storage {
+		values[k] = v
+	}
+	values["admin"] = d.Admin
+	values["trustees"] = trustees
+	values["exhibition_title"] = textValue(fields["exhibition_title"], d.ExhibitionTitle)
+	values["token_metadata_base"] = textValue(fields["token_metadata_base"], d.TokenMetadataBase)
+
+	for label, f := range fields {
+		if _, ok := values[label]; ok {
+			continue
+		}
+		switch f.OpCode {
+		case micheline.T_MAP, micheline.T_BIG_MAP:
+			values[label] = map[string]interface{}{}
+		case micheline.T_SET, micheline.T_LIST:
+			values[label] = []interface{}{}
+		}
+	}
+
+	storage, err := tezos.EncodeValue(typ, values)
+	if err != nil {
+		return nil, err
+	}
+	script.Storage = storage
+	return script, nil
+}
+
+// ParseContractCode parses the compiled Micheline of a contract in its JSON form
+func ParseContractCode(b []byte) (micheline.Code, error) {
+	var code micheline.Code
+	if len(bytes.TrimSpace(b)) == 0 {
+		return code, ErrContractCodeUnavailable
+	}
+	if err := json.Unmarshal(b, &code); err != nil {
+		return code, ErrInvalidContractCode
+	}
+	return code, nil
+}
+
+// Deploy originates an exhibition contract of the code and returns its address
+// and the operation hash once the origination is included
+func Deploy(w *tezos.Wallet, code micheline.Code, d DeployParam) (string, string, error) {
+	script, err := d.Build(code)
+	if err != nil {
+		return "", "", err
+	}
+	return w.OriginateScript(*script)
+}
+
+// storageFields collects the annotated fields of a storage type
+func storageFields(typ micheline.Prim, fields map[string]micheline.Prim) {
+	if label := typ.GetFieldAnnoAny(); label != "" {
+		fields[label] = typ
+		return
+	}
+	if typ.OpCode == micheline.T_PAIR {
+		for _, a := range typ.Args {
+			storageFields(a, fields)
+		}
+	}
+}
+
+// textValue returns a text for a string field, or its hex encoding for a bytes field
+func textValue(typ micheline.Prim, s string) string {
+	if typ.OpCode == micheline.T_BYTES {
+		return hex.EncodeToString([]byte(s))
+	}
+	return s
+}
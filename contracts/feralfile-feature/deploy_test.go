@@ -0,0 +1,88 @@
+package feralfilefeature
+
+import (
+	"encoding/json"
+	"testing"
+
+	"blockwatch.cc/tzgo/micheline"
+	"github.com/stretchr/testify/assert"
+)
+
+const testExhibitionCode = `[{"prim":"parameter","args":[{"prim":"unit"}]},` +
+	`{"prim":"storage","args":[{"prim":"pair","args":[` +
+	`{"prim":"pair","args":[{"prim":"address","annots":["%admin"]},{"prim":"set","args":[{"prim":"address"}],"annots":["%trustees"]}]},` +
+	`{"prim":"pair","args":[{"prim":"string","annots":["%exhibition_title"]},` +
+	`{"prim":"pair","args":[{"prim":"bytes","annots":["%token_metadata_base"]},` +
+	`{"prim":"pair","args":[{"prim":"big_map","args":[{"prim":"nat"},{"prim":"address"}],"annots":["%ledger"]},{"prim":"nat","annots":["%max_edition"]}]}]}]}]}]},` +
+	`{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`
+
+func testDeployParam() DeployParam {
+	return DeployParam{
+		Admin:             "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+		ExhibitionTitle:   "Exhibition",
+		TokenMetadataBase: "ipfs://",
+		Storage:           map[string]interface{}{"max_edition": 10},
+	}
+}
+
+func TestDeployParamBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		trustees []string
+		storage  string
+	}{
+		{
+			name:    "no trustees",
+			storage: `{"prim":"Pair","args":[{"prim":"Pair","args":[{"bytes":"0000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"},[]]},{"prim":"Pair","args":[{"string":"Exhibition"},{"prim":"Pair","args":[{"bytes":"697066733a2f2f"},{"prim":"Pair","args":[[],{"int":"10"}]}]}]}]}`,
+		},
+		{
+			name:     "trustees",
+			trustees: []string{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"},
+			storage:  `{"prim":"Pair","args":[{"prim":"Pair","args":[{"bytes":"0000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"},[{"bytes":"0000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"}]]},{"prim":"Pair","args":[{"string":"Exhibition"},{"prim":"Pair","args":[{"bytes":"697066733a2f2f"},{"prim":"Pair","args":[[],{"int":"10"}]}]}]}]}`,
+		},
+	}
+
+	code, err := ParseContractCode([]byte(testExhibitionCode))
+	assert.Nil(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDeployParam()
+			d.Trustees = tt.trustees
+			script, err := d.Build(code)
+			assert.Nil(t, err)
+			b, err := json.Marshal(script.Storage)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.storage, string(b))
+		})
+	}
+}
+
+func TestDeployParamBuildInvalid(t *testing.T) {
+	code, err := ParseContractCode([]byte(testExhibitionCode))
+	assert.Nil(t, err)
+
+	d := testDeployParam()
+	d.Trustees = []string{"invalid"}
+	_, err = d.Build(code)
+	assert.Equal(t, ErrInvalidAddress, err)
+
+	d = testDeployParam()
+	d.Admin = ""
+	_, err = d.Build(code)
+	assert.Equal(t, ErrInvalidAddress, err)
+
+	_, err = testDeployParam().Build(micheline.Code{})
+	assert.Equal(t, ErrInvalidContractCode, err)
+}
+
+func TestParseContractCode(t *testing.T) {
+	_, err := ParseContractCode(nil)
+	assert.Equal(t, ErrContractCodeUnavailable, err)
+	_, err = ParseContractCode([]byte("{"))
+	assert.Equal(t, ErrInvalidContractCode, err)
+
+	// the code argument of a deploy is not used
+	var d DeployParam
+	assert.Nil(t, json.Unmarshal([]byte(`{"code":[],"admin":"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"}`), &d))
+	assert.EqualValues(t, DeployParam{Admin: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"}, d)
+}
@@ -3,8 +3,14 @@ package feralfilefeature
 import "errors"
 
 var (
-	ErrInvalidAddress   = errors.New("Invalid address provided")
-	ErrInvalidPublicKey = errors.New("Invalid public key provided")
-	ErrInvalidSignature = errors.New("Invalid signature provided")
-	ErrInvalidTokenID   = errors.New("Invalid tokenID provided")
+	ErrInvalidAddress          = errors.New("Invalid address provided")
+	ErrInvalidPublicKey        = errors.New("Invalid public key provided")
+	ErrInvalidSignature        = errors.New("Invalid signature provided")
+	ErrInvalidTokenID          = errors.New("Invalid tokenID provided")
+	ErrInvalidAmount           = errors.New("Invalid amount provided")
+	ErrInvalidNonce            = errors.New("Invalid nonce provided")
+	ErrInvalidContractCode     = errors.New("Invalid contract code provided")
+	ErrContractCodeUnavailable = errors.New("Contract code is not available")
+	ErrInvalidParameters       = errors.New("Invalid call parameters")
+	ErrUnexpectedEntrypoint    = errors.New("Unexpected entrypoint")
 )
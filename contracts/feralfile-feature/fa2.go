@@ -52,7 +52,7 @@ func Transfer(w *tezos.Wallet, con *contract.Contract, tps []TransferParam) (*st
 			return nil, err
 		}
 		args.WithTransfer(
-			w.PrivateKey().Address(),
+			w.Address(),
 			tp_.To,
 			(tz.Z)(*tp_.TokenID),
 			tz.NewZ(1),
@@ -0,0 +1,83 @@
+package feralfilefeature
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+// PrepareAuthTransferParam signs the authorized transfers of the tokens owned by
+// the account of an index and returns the param for AuthTransfer. Every
+// signature is verified with the public key of the owner before returning.
+func PrepareAuthTransferParam(w *tezos.Wallet, ownerIndex uint, contractAddress string, tps []TransferParam, expiry time.Time) (*AuthTransferParam, error) {
+	return prepareAuthTransferParam(w, ownerIndex, contractAddress, tps, expiry, tezos.AuthTransferFormatV1, 0)
+}
+
+// PrepareAuthTransferParamV2 signs the authorized transfers in the V2 format. The
+// first transfer is signed with the current nonce of the owner in the contract,
+// and each following one with the next nonce.
+func PrepareAuthTransferParamV2(w *tezos.Wallet, ownerIndex uint, contractAddress string, tps []TransferParam, expiry time.Time, nonce int64) (*AuthTransferParam, error) {
+	return prepareAuthTransferParam(w, ownerIndex, contractAddress, tps, expiry, tezos.AuthTransferFormatV2, nonce)
+}
+
+func prepareAuthTransferParam(w *tezos.Wallet, ownerIndex uint, contractAddress string, tps []TransferParam, expiry time.Time, format tezos.AuthTransferFormat, nonce int64) (*AuthTransferParam, error) {
+	owner := w
+	if ownerIndex != w.AccountIndex() {
+		var err error
+		owner, err = w.DeriveAccount(ownerIndex)
+		if err != nil {
+			return nil, err
+		}
+		// the derived key is only needed for signing
+		defer owner.Wipe()
+	}
+
+	pk, err := owner.Signer().GetKey(context.Background(), owner.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	ap := &AuthTransferParam{
+		From:   owner.Account(),
+		PK:     pk.String(),
+		Expiry: expiry,
+		Format: format,
+	}
+	for i, tp := range tps {
+		m := tezos.AuthTransferMessage{
+			Format:   format,
+			ChainID:  w.ChainID(),
+			Contract: contractAddress,
+			To:       tp.To,
+			TokenID:  tp.TokenID,
+			Expiry:   expiry,
+		}
+		tx := AuthTransaction{
+			To:      tp.To,
+			TokenID: tp.TokenID,
+		}
+		if format == tezos.AuthTransferFormatV2 {
+			m.Amount = 1
+			m.Nonce = nonce + int64(i)
+			tx.Nonce = strconv.FormatInt(m.Nonce, 10)
+		}
+
+		sig, err := owner.SignAuthTransfer(m)
+		if err != nil {
+			return nil, err
+		}
+		if err := tezos.VerifyAuthTransfer(ap.PK, m, sig); err != nil {
+			return nil, err
+		}
+		tx.Signature = sig
+		ap.Txs = append(ap.Txs, tx)
+	}
+
+	// make sure the param can be encoded for the contract call
+	if _, err := ap.Build(); err != nil {
+		return nil, err
+	}
+	return ap, nil
+}
@@ -0,0 +1,87 @@
+package feralfilefeature
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+func TestPrepareAuthTransferParam(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := tezos.NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	contract := "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"
+	expiry := time.Unix(1700000000, 0)
+	tps := []TransferParam{
+		{To: "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", TokenID: "5"},
+		{To: "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", TokenID: "6"},
+	}
+
+	tests := []struct {
+		name    string
+		format  tezos.AuthTransferFormat
+		prepare func() (*AuthTransferParam, error)
+		nonces  []string
+	}{
+		{
+			name:   "v1",
+			format: tezos.AuthTransferFormatV1,
+			prepare: func() (*AuthTransferParam, error) {
+				return PrepareAuthTransferParam(w, 1, contract, tps, expiry)
+			},
+			nonces: []string{"", ""},
+		},
+		{
+			name:   "v2",
+			format: tezos.AuthTransferFormatV2,
+			prepare: func() (*AuthTransferParam, error) {
+				return PrepareAuthTransferParamV2(w, 1, contract, tps, expiry, 7)
+			},
+			nonces: []string{"7", "8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ap, err := tt.prepare()
+			assert.Nil(t, err)
+			// the transfers are signed by the owner account
+			assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", ap.From)
+			assert.EqualValues(t, tt.format, ap.Format)
+			assert.EqualValues(t, expiry, ap.Expiry)
+			assert.Len(t, ap.Txs, len(tps))
+
+			for i, tx := range ap.Txs {
+				assert.EqualValues(t, tps[i].To, tx.To)
+				assert.EqualValues(t, tps[i].TokenID, tx.TokenID)
+				assert.EqualValues(t, tt.nonces[i], tx.Nonce)
+
+				m := tezos.AuthTransferMessage{
+					Format:   tt.format,
+					Contract: contract,
+					To:       tx.To,
+					TokenID:  tx.TokenID,
+					Expiry:   expiry,
+				}
+				if tt.format == tezos.AuthTransferFormatV2 {
+					m.ChainID = w.ChainID()
+					m.Amount = 1
+					m.Nonce = 7 + int64(i)
+				}
+				assert.Nil(t, tezos.VerifyAuthTransfer(ap.PK, m, tx.Signature))
+
+				// the signature is bound to the token
+				m.TokenID = "9"
+				assert.NotNil(t, tezos.VerifyAuthTransfer(ap.PK, m, tx.Signature))
+			}
+		})
+	}
+
+	_, err = PrepareAuthTransferParam(w, 1, contract, []TransferParam{{To: "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", TokenID: "x"}}, expiry)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,177 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"blockwatch.cc/tzgo/contract"
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+var (
+	ErrInvalidAddress    = errors.New("Invalid address provided")
+	ErrUnknownEntrypoint = errors.New("Entrypoint is not found in the contract")
+)
+
+// GenericContract calls any contract without a binding. The script of the
+// contract is loaded from the node to resolve the type of the entrypoint.
+type GenericContract struct {
+	contractAddress string
+}
+
+var _ tezos.ResultContract = (*GenericContract)(nil)
+
+func GenericContractFactory(contractAddress string) tezos.Contract {
+	return &GenericContract{
+		contractAddress: contractAddress,
+	}
+}
+
+// DeployParam is the argument for deploying a contract. Code and storage are
+// Micheline JSON or hex encoded binary Micheline as a JSON string.
+type DeployParam struct {
+	Code     json.RawMessage `json:"code"`
+	Storage  json.RawMessage `json:"storage"`
+	Delegate string          `json:"delegate,omitempty"`
+	Balance  int64           `json:"balance,omitempty"`
+}
+
+// Deploy deploys the smart contract to tezos blockchain
+func (c *GenericContract) Deploy(wallet *tezos.Wallet, arguments json.RawMessage) (string, string, error) {
+	var param DeployParam
+	if err := json.Unmarshal(arguments, &param); err != nil {
+		return "", "", err
+	}
+
+	var opts []tezos.OriginationOption
+	if param.Delegate != "" {
+		opts = append(opts, tezos.WithOriginationDelegate(param.Delegate))
+	}
+	if param.Balance != 0 {
+		opts = append(opts, tezos.WithOriginationBalance(param.Balance))
+	}
+	return wallet.Originate(michelineText(param.Code), michelineText(param.Storage), opts...)
+}
+
+// Call is the entry function for account vault to interact with a smart contract.
+// The arguments are a JSON value of the entrypoint type as accepted by
+// tezos.EncodeValue, or Micheline JSON wrapped like {"micheline": ...}.
+func (c *GenericContract) Call(wallet *tezos.Wallet, method string, arguments json.RawMessage) (*string, error) {
+	args, err := c.callArgs(wallet, method, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.Send(args)
+}
+
+// CallWithResult calls a contract like Call and returns the result with the simulated costs
+func (c *GenericContract) CallWithResult(wallet *tezos.Wallet, method string, arguments json.RawMessage) (*tezos.CallResult, error) {
+	args, err := c.callArgs(wallet, method, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.SendWithResult(args)
+}
+
+// callArgs encodes the arguments of a call with the script of the contract
+func (c *GenericContract) callArgs(wallet *tezos.Wallet, method string, arguments json.RawMessage) (contract.CallArguments, error) {
+	if wallet.IsOffline() {
+		return nil, tezos.ErrOfflineWallet
+	}
+	ca, err := tz.ParseAddress(c.contractAddress)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+
+	script, err := wallet.RPCClient().GetNormalizedScript(context.Background(), ca, rpc.UnparsingModeReadable)
+	if err != nil {
+		return nil, err
+	}
+	params, err := EncodeParameters(script, method, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	args := contract.NewTxArgs()
+	args.WithParameters(*params)
+	args.WithDestination(ca)
+	return args, nil
+}
+
+// EncodeParameters encodes the arguments of an entrypoint call with the type
+// of the entrypoint in a script
+func EncodeParameters(script *micheline.Script, entrypoint string, arguments json.RawMessage) (*micheline.Parameters, error) {
+	if entrypoint == "" {
+		entrypoint = micheline.DEFAULT
+	}
+
+	var typ micheline.Type
+	eps, err := script.Entrypoints(true)
+	if err != nil {
+		return nil, err
+	}
+	if ep, ok := eps[entrypoint]; ok {
+		typ = ep.Type()
+	} else if entrypoint == micheline.DEFAULT {
+		typ = script.ParamType()
+	} else {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEntrypoint, entrypoint)
+	}
+
+	var value micheline.Prim
+	if code, ok := michelineArgument(arguments); ok {
+		if err := json.Unmarshal(code, &value); err != nil {
+			return nil, err
+		}
+	} else {
+		var v interface{}
+		if len(arguments) > 0 {
+			// numbers are kept as text so big nats are not rounded
+			d := json.NewDecoder(bytes.NewReader(arguments))
+			d.UseNumber()
+			if err := d.Decode(&v); err != nil {
+				return nil, err
+			}
+		}
+		if value, err = tezos.EncodeValue(typ, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &micheline.Parameters{
+		Entrypoint: entrypoint,
+		Value:      value,
+	}, nil
+}
+
+// michelineArgument returns the Micheline JSON of arguments given like
+// {"micheline": ...}
+func michelineArgument(data json.RawMessage) (json.RawMessage, bool) {
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v); err != nil || len(v) != 1 {
+		return nil, false
+	}
+	code, ok := v["micheline"]
+	return code, ok
+}
+
+// michelineText returns the hex of binary Micheline given as a JSON string, or
+// the Micheline JSON as it is
+func michelineText(data json.RawMessage) []byte {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return []byte(s)
+	}
+	return data
+}
+
+func init() {
+	tezos.RegisterContract("Generic", GenericContractFactory)
+}
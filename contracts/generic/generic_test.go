@@ -0,0 +1,94 @@
+package generic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"blockwatch.cc/tzgo/micheline"
+	"github.com/stretchr/testify/assert"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+const testCode = `[{"prim":"parameter","args":[{"prim":"or","args":[` +
+	`{"prim":"nat","annots":["%burn"]},` +
+	`{"prim":"or","args":[` +
+	`{"prim":"pair","annots":["%mint"],"args":[{"prim":"address","annots":["%owner"]},{"prim":"nat","annots":["%token_id"]}]},` +
+	`{"prim":"pair","annots":["%set"],"args":[{"prim":"int","annots":["%int"]},{"prim":"string","annots":["%string"]}]}]}]}]},` +
+	`{"prim":"storage","args":[{"prim":"unit"}]},` +
+	`{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`
+
+func TestEncodeParameters(t *testing.T) {
+	script, err := tezos.ParseScript([]byte(testCode), []byte(`{"prim":"Unit"}`))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		entrypoint string
+		arguments  string
+		value      string
+		err        error
+	}{
+		{
+			name:       "nat",
+			entrypoint: "burn",
+			arguments:  `1234567890123456789`,
+			value:      `{"int":"1234567890123456789"}`,
+		},
+		{
+			name:       "record",
+			entrypoint: "mint",
+			arguments:  `{"owner":"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa","token_id":"18446744073709551617"}`,
+			value:      `{"prim":"Pair","args":[{"bytes":"0000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"},{"int":"18446744073709551617"}]}`,
+		},
+		{
+			name:       "record with micheline like fields",
+			entrypoint: "set",
+			arguments:  `{"int":-5,"string":"a"}`,
+			value:      `{"prim":"Pair","args":[{"int":"-5"},{"string":"a"}]}`,
+		},
+		{
+			name:       "micheline",
+			entrypoint: "set",
+			arguments:  `{"micheline":{"prim":"Pair","args":[{"int":"7"},{"string":"b"}]}}`,
+			value:      `{"prim":"Pair","args":[{"int":"7"},{"string":"b"}]}`,
+		},
+		{
+			name:       "default entrypoint",
+			entrypoint: "",
+			arguments:  `{"micheline":{"prim":"Left","args":[{"int":"1"}]}}`,
+			value:      `{"prim":"Left","args":[{"int":"1"}]}`,
+		},
+		{
+			name:       "invalid nat",
+			entrypoint: "burn",
+			arguments:  `-1`,
+			err:        tezos.ErrInvalidMichelsonValue,
+		},
+		{
+			name:       "unknown entrypoint",
+			entrypoint: "transfer",
+			arguments:  `1`,
+			err:        ErrUnknownEntrypoint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := EncodeParameters(script, tt.entrypoint, json.RawMessage(tt.arguments))
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			var value micheline.Prim
+			assert.Nil(t, json.Unmarshal([]byte(tt.value), &value))
+			assert.EqualValues(t, value, params.Value)
+			if tt.entrypoint == "" {
+				assert.EqualValues(t, micheline.DEFAULT, params.Entrypoint)
+			} else {
+				assert.EqualValues(t, tt.entrypoint, params.Entrypoint)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package tezos
+
+import (
+	"context"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// SetDelegate delegates the balance of the wallet account to a baker
+func (w *Wallet) SetDelegate(baker string) (*string, error) {
+	addr, err := tezos.ParseAddress(baker)
+	if err != nil || !addr.IsEOA() {
+		return nil, ErrInvalidAddress
+	}
+	op, opts := w.delegationOp(addr)
+	return w.send(op, opts)
+}
+
+// WithdrawDelegate removes the delegate of the wallet account
+func (w *Wallet) WithdrawDelegate() (*string, error) {
+	op, opts := w.delegationOp(tezos.InvalidAddress)
+	return w.send(op, opts)
+}
+
+// Delegate returns the current delegate of the wallet account. It is empty
+// when the account is not delegated.
+func (w *Wallet) Delegate() (string, error) {
+	if w.IsOffline() {
+		return "", ErrOfflineWallet
+	}
+
+	info, err := w.rpcClient.GetContract(context.Background(), w.address, rpc.Head)
+	if err != nil {
+		return "", err
+	}
+	if !info.Delegate.IsValid() {
+		return "", nil
+	}
+	return info.Delegate.String(), nil
+}
+
+// delegationOp constructs an operation which sets the delegate of the wallet
+// account, or removes it for an invalid address
+func (w *Wallet) delegationOp(baker tezos.Address) (*codec.Op, *rpc.CallOptions) {
+	opts := &rpc.CallOptions{
+		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
+		MaxFee: 1_000_000,
+	}
+
+	op := codec.NewOp().WithTTL(opts.TTL)
+	if baker.IsValid() {
+		op.WithDelegation(baker)
+	} else {
+		op.WithUndelegation()
+	}
+
+	op.WithParams(w.params())
+
+	return op, opts
+}
@@ -0,0 +1,53 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegate(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	_, err = w.SetDelegate("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+	_, err = w.SetDelegate("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX")
+	assert.EqualError(t, err, ErrInvalidAddress.Error())
+	_, err = w.Delegate()
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+
+	baker, _ := tezos.ParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	op, _ := w.delegationOp(baker)
+	assert.Len(t, op.Contents, 1)
+	assert.EqualValues(t, baker, op.Contents[0].(*codec.Delegation).Delegate)
+	op, _ = w.delegationOp(tezos.InvalidAddress)
+	assert.False(t, op.Contents[0].(*codec.Delegation).Delegate.IsValid())
+
+	contract := `{"balance":"1000000","counter":"42"}`
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "/chains/main/blocks/head/context/contracts/"+w.Account(), r.URL.Path)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(contract))
+	}))
+	defer node.Close()
+	c, err := rpc.NewClient(node.URL, nil)
+	assert.Nil(t, err)
+	w.rpcClient = c
+
+	d, err := w.Delegate()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", d)
+
+	contract = `{"balance":"1000000","delegate":"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa","counter":"42"}`
+	d, err = w.Delegate()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", d)
+}
@@ -0,0 +1,182 @@
+package tezos
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// DerivationIndexPlaceholder is the placeholder of the account index in a derivation path template
+const DerivationIndexPlaceholder = "{index}"
+
+// DerivationScheme is a named derivation path template for the wallet accounts
+type DerivationScheme struct {
+	Name     string `json:"name"`
+	Template string `json:"template"`
+}
+
+var (
+	// DefaultDerivationScheme varies the account segment of the BIP44 path. It is
+	// the layout used by this vault and most software wallets like Temple, Kukai and Umami.
+	DefaultDerivationScheme = DerivationScheme{
+		Name:     "default",
+		Template: "m/44'/1729'/{index}'/0'",
+	}
+
+	// LastSegmentDerivationScheme keeps the account segment at zero and varies the last segment
+	LastSegmentDerivationScheme = DerivationScheme{
+		Name:     "last-segment",
+		Template: "m/44'/1729'/0'/{index}'",
+	}
+
+	// LegacyLedgerDerivationScheme is the legacy Ledger layout without the change segment
+	LegacyLedgerDerivationScheme = DerivationScheme{
+		Name:     "legacy-ledger",
+		Template: "m/44'/1729'/{index}'",
+	}
+)
+
+// derivationPresets are the derivation schemes of popular Tezos wallets
+var derivationPresets = map[string]DerivationScheme{
+	"vault":         DefaultDerivationScheme,
+	"temple":        DefaultDerivationScheme,
+	"kukai":         DefaultDerivationScheme,
+	"umami":         DefaultDerivationScheme,
+	"ledger-live":   DefaultDerivationScheme,
+	"galleon":       LastSegmentDerivationScheme,
+	"ledger-legacy": LegacyLedgerDerivationScheme,
+}
+
+// NewDerivationScheme creates a derivation scheme from a path template like
+// m/44'/1729'/{index}'/0'. The template must contain the index placeholder once.
+func NewDerivationScheme(name, template string) (DerivationScheme, error) {
+	s := DerivationScheme{
+		Name:     name,
+		Template: template,
+	}
+	if strings.Count(template, DerivationIndexPlaceholder) != 1 {
+		return s, ErrInvalidDerivationScheme
+	}
+	if _, err := parseDerivePath(s.Path(0)); err != nil {
+		return s, ErrInvalidDerivationScheme
+	}
+	return s, nil
+}
+
+// DerivationPreset returns the derivation scheme of a wallet by name
+func DerivationPreset(wallet string) (DerivationScheme, bool) {
+	s, ok := derivationPresets[wallet]
+	return s, ok
+}
+
+// DerivationPresets returns the names of the wallets which have a derivation preset
+func DerivationPresets() []string {
+	names := make([]string, 0, len(derivationPresets))
+	for n := range derivationPresets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Path returns the derivation path of an account index
+func (s DerivationScheme) Path(index uint) string {
+	return strings.Replace(s.Template, DerivationIndexPlaceholder, strconv.FormatUint(uint64(index), 10), 1)
+}
+
+// WithDerivationScheme sets the derivation path scheme of the wallet accounts
+func WithDerivationScheme(scheme DerivationScheme) WalletOption {
+	return func(o *walletOptions) {
+		o.scheme = scheme
+	}
+}
+
+// DerivationMatch is an account found by scanning the derivation schemes
+type DerivationMatch struct {
+	Scheme  DerivationScheme
+	KeyType tezos.KeyType
+	Index   uint
+}
+
+// FindDerivationScheme scans the distinct derivation schemes of all presets up
+// to a max account index for the account of an address. The key type is
+// determined by the address prefix. The keys derived for the scan are wiped.
+func FindDerivationScheme(seed []byte, address string, maxIndex uint) (*DerivationMatch, error) {
+	addr, err := tezos.ParseAddress(address)
+	if err != nil || !addr.IsEOA() {
+		return nil, ErrInvalidAddress
+	}
+
+	mk, err := newMasterKey(seed, addr.KeyType())
+	if err != nil {
+		return nil, err
+	}
+	defer mk.wipe()
+
+	for _, s := range distinctDerivationSchemes() {
+		for i := uint(0); i <= maxIndex; i++ {
+			key, err := mk.derivePrivateKey(s.Path(i))
+			if err != nil {
+				return nil, err
+			}
+			found := key.Address().Equal(addr)
+			wipeBytes(key.Data)
+			if found {
+				return &DerivationMatch{
+					Scheme:  s,
+					KeyType: addr.KeyType(),
+					Index:   i,
+				}, nil
+			}
+		}
+	}
+	return nil, ErrAccountNotFound
+}
+
+// ImportWallet finds the derivation scheme of a known address and returns the
+// wallet account of it. The returned wallet owns the master key.
+func ImportWallet(seed []byte, address string, maxIndex uint, network string, rpcURL string) (*Wallet, error) {
+	m, err := FindDerivationScheme(seed, address, maxIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewWallet(seed, network, rpcURL, WithKeyType(m.KeyType), WithDerivationScheme(m.Scheme))
+	if err != nil {
+		return nil, err
+	}
+	if m.Index == DefaultAccountIndex {
+		return w, nil
+	}
+	return w.takeAccount(m.Index)
+}
+
+// takeAccount derives an account which takes over the master key of the
+// wallet, and wipes the wallet. The wallet is wiped on failure too.
+func (w *Wallet) takeAccount(index uint) (*Wallet, error) {
+	defer w.Wipe()
+
+	a, err := w.DeriveAccount(index)
+	if err != nil {
+		return nil, err
+	}
+	a.ownsMaster, w.ownsMaster = w.ownsMaster, false
+	return a, nil
+}
+
+// distinctDerivationSchemes returns the derivation schemes of the presets
+// without duplicates, the default one first
+func distinctDerivationSchemes() []DerivationScheme {
+	schemes := []DerivationScheme{DefaultDerivationScheme}
+	seen := map[string]bool{DefaultDerivationScheme.Template: true}
+	for _, n := range DerivationPresets() {
+		s := derivationPresets[n]
+		if !seen[s.Template] {
+			seen[s.Template] = true
+			schemes = append(schemes, s)
+		}
+	}
+	return schemes
+}
@@ -0,0 +1,110 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerivationScheme(t *testing.T) {
+	assert.EqualValues(t, "m/44'/1729'/3'/0'", DefaultDerivationScheme.Path(3))
+	assert.EqualValues(t, "m/44'/1729'/0'/3'", LastSegmentDerivationScheme.Path(3))
+	assert.EqualValues(t, "m/44'/1729'/3'", LegacyLedgerDerivationScheme.Path(3))
+	assert.EqualValues(t, buildDerivePath(7), DefaultDerivationScheme.Path(7))
+
+	s, err := NewDerivationScheme("custom", "m/44'/1729'/1'/{index}'/0'")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "m/44'/1729'/1'/2'/0'", s.Path(2))
+
+	_, err = NewDerivationScheme("custom", "m/44'/1729'/0'/0'")
+	assert.EqualError(t, err, ErrInvalidDerivationScheme.Error())
+	_, err = NewDerivationScheme("custom", "m/44'/1729'/{index}'/{index}'")
+	assert.EqualError(t, err, ErrInvalidDerivationScheme.Error())
+	_, err = NewDerivationScheme("custom", "44'/1729'/{index}'")
+	assert.EqualError(t, err, ErrInvalidDerivationScheme.Error())
+
+	p, ok := DerivationPreset("galleon")
+	assert.True(t, ok)
+	assert.EqualValues(t, LastSegmentDerivationScheme, p)
+	_, ok = DerivationPreset("unknown")
+	assert.False(t, ok)
+	assert.Contains(t, DerivationPresets(), "temple")
+}
+
+func TestWalletDerivationScheme(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+
+	w, err := NewOfflineWallet(seed, "NetXdQprcVkpaWU", WithDerivationScheme(LegacyLedgerDerivationScheme))
+	assert.Nil(t, err)
+	assert.EqualValues(t, LegacyLedgerDerivationScheme, w.DerivationScheme())
+
+	mk, err := newMasterKey(seed, tezos.KeyTypeEd25519)
+	assert.Nil(t, err)
+	key, err := mk.derivePrivateKey("m/44'/1729'/0'")
+	assert.Nil(t, err)
+	assert.EqualValues(t, key.Address().String(), w.Account())
+
+	dw, err := w.DeriveAccount(2)
+	assert.Nil(t, err)
+	assert.EqualValues(t, LegacyLedgerDerivationScheme, dw.DerivationScheme())
+	key, err = mk.derivePrivateKey("m/44'/1729'/2'")
+	assert.Nil(t, err)
+	assert.EqualValues(t, key.Address().String(), dw.Account())
+}
+
+func TestFindDerivationScheme(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+
+	m, err := FindDerivationScheme(seed, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", 5)
+	assert.Nil(t, err)
+	assert.EqualValues(t, DefaultDerivationScheme, m.Scheme)
+	assert.EqualValues(t, tezos.KeyTypeEd25519, m.KeyType)
+	assert.EqualValues(t, 1, m.Index)
+
+	mk, err := newMasterKey(seed, tezos.KeyTypeSecp256k1)
+	assert.Nil(t, err)
+	key, err := mk.derivePrivateKey(LastSegmentDerivationScheme.Path(3))
+	assert.Nil(t, err)
+
+	m, err = FindDerivationScheme(seed, key.Address().String(), 5)
+	assert.Nil(t, err)
+	assert.EqualValues(t, LastSegmentDerivationScheme, m.Scheme)
+	assert.EqualValues(t, tezos.KeyTypeSecp256k1, m.KeyType)
+	assert.EqualValues(t, 3, m.Index)
+
+	_, err = FindDerivationScheme(seed, key.Address().String(), 2)
+	assert.EqualError(t, err, ErrAccountNotFound.Error())
+
+	_, err = FindDerivationScheme(seed, "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", 5)
+	assert.EqualError(t, err, ErrInvalidAddress.Error())
+}
+
+func TestTakeAccount(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(seed, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	key := w.privateKey
+
+	a, err := w.takeAccount(1)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", a.Account())
+	assert.True(t, a.ownsMaster)
+	assert.EqualValues(t, make([]byte, len(key.Data)), key.Data)
+	_, err = w.DeriveAccount(2)
+	assert.EqualError(t, err, ErrMasterKeyUnavailable.Error())
+
+	// the account wipes the master key it took over
+	dw, err := a.DeriveAccount(2)
+	assert.Nil(t, err)
+	assert.Nil(t, a.Close())
+	_, err = dw.DeriveAccount(3)
+	assert.EqualError(t, err, ErrKeyWiped.Error())
+
+	w, err = NewOfflineWallet(seed, "NetXdQprcVkpaWU", WithDerivedKeyOnly())
+	assert.Nil(t, err)
+	_, err = w.takeAccount(1)
+	assert.EqualError(t, err, ErrMasterKeyUnavailable.Error())
+	assert.False(t, w.PrivateKey().IsValid())
+}
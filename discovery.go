@@ -0,0 +1,110 @@
+package tezos
+
+import (
+	"context"
+	"net/http"
+
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// DefaultDiscoveryGapLimit is the number of consecutive unused accounts after
+// which the discovery stops
+const DefaultDiscoveryGapLimit = 20
+
+// DiscoveredAccount is an active account found by the account discovery
+type DiscoveredAccount struct {
+	Index    uint          `json:"index"`
+	Address  tezos.Address `json:"address"`
+	Balance  int64         `json:"balance"`
+	Counter  int64         `json:"counter"`
+	Revealed bool          `json:"revealed"`
+}
+
+// IsActive returns whether the account has been used on chain
+func (a DiscoveredAccount) IsActive() bool {
+	return a.Revealed || a.Balance > 0 || a.Counter > 0
+}
+
+// DiscoverAccounts walks the derivation indexes from zero and asks the node
+// whether each account has been revealed, has a balance or a counter. It stops
+// after a gap limit of consecutive unused accounts and returns the active accounts.
+func (w *Wallet) DiscoverAccounts(gapLimit uint) ([]DiscoveredAccount, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+	if w.masterKey == nil {
+		return nil, ErrMasterKeyUnavailable
+	}
+	if gapLimit == 0 {
+		gapLimit = DefaultDiscoveryGapLimit
+	}
+
+	ctx := context.Background()
+	accounts := []DiscoveredAccount{}
+	for i, gap := uint(0), uint(0); gap < gapLimit; i++ {
+		s, err := w.DeriveSigner(i)
+		if err != nil {
+			return nil, err
+		}
+		// only the address is needed
+		addr := s.key.Address()
+		s.Wipe()
+
+		a, err := w.accountState(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		a.Index = i
+
+		if !a.IsActive() {
+			gap++
+			continue
+		}
+		gap = 0
+		accounts = append(accounts, *a)
+	}
+	return accounts, nil
+}
+
+// accountState queries the balance, counter and reveal state of an implicit account
+func (w *Wallet) accountState(ctx context.Context, addr tezos.Address) (*DiscoveredAccount, error) {
+	a := &DiscoveredAccount{
+		Address: addr,
+	}
+
+	info, err := w.rpcClient.GetContract(ctx, addr, rpc.Head)
+	if err != nil {
+		// an account which has never been allocated is unknown to the node
+		if rpc.ErrorStatus(err) == http.StatusNotFound {
+			return a, nil
+		}
+		return nil, err
+	}
+	a.Balance = info.Balance
+	a.Counter = info.Counter
+
+	// only an allocated account has a counter and can be revealed
+	if a.Counter > 0 {
+		key, err := w.rpcClient.GetManagerKey(ctx, addr, rpc.Head)
+		if err != nil {
+			return nil, err
+		}
+		a.Revealed = key.IsValid()
+	}
+	return a, nil
+}
+
+// Discover runs the account discovery on the root wallet and caches the active accounts
+func (m *MultiAccountWallet) Discover(gapLimit uint) ([]DiscoveredAccount, error) {
+	accounts, err := m.root.DiscoverAccounts(gapLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		if _, err := m.Account(a.Index); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
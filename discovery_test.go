@@ -0,0 +1,74 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blockwatch.cc/tzgo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverAccounts(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	a2, err := w.DeriveSigner(2)
+	assert.Nil(t, err)
+	a4, err := w.DeriveSigner(4)
+	assert.Nil(t, err)
+
+	// account 0 is revealed, account 2 only received xtz and account 4 is drained
+	contracts := map[string]string{
+		w.Account():                                `{"balance":"1000000","counter":"42"}`,
+		a2.key.Address().String():                  `{"balance":"500","counter":"100"}`,
+		a4.key.Address().String():                  `{"balance":"0","counter":"120"}`,
+		w.Account() + "/manager_key":               `"` + w.privateKey.Public().String() + `"`,
+		a2.key.Address().String() + "/manager_key": `null`,
+		a4.key.Address().String() + "/manager_key": `null`,
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		p := strings.TrimPrefix(r.URL.Path, "/chains/main/blocks/head/context/contracts/")
+		b, ok := contracts[p]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(b))
+	}))
+	defer node.Close()
+
+	c, err := rpc.NewClient(node.URL, nil)
+	assert.Nil(t, err)
+	w.rpcClient = c
+
+	accounts, err := w.DiscoverAccounts(3)
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 3)
+	assert.EqualValues(t, 0, accounts[0].Index)
+	assert.EqualValues(t, w.Account(), accounts[0].Address.String())
+	assert.EqualValues(t, 1000000, accounts[0].Balance)
+	assert.True(t, accounts[0].Revealed)
+	assert.EqualValues(t, 2, accounts[1].Index)
+	assert.EqualValues(t, 500, accounts[1].Balance)
+	assert.False(t, accounts[1].Revealed)
+	assert.EqualValues(t, 4, accounts[2].Index)
+	assert.EqualValues(t, 120, accounts[2].Counter)
+
+	accounts, err = w.DiscoverAccounts(1)
+	assert.Nil(t, err)
+	assert.Len(t, accounts, 1)
+
+	m := NewMultiAccountWallet(w)
+	_, err = m.Discover(2)
+	assert.Nil(t, err)
+	assert.Len(t, m.Accounts(), 3)
+
+	w.rpcClient = nil
+	_, err = w.DiscoverAccounts(1)
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+}
@@ -0,0 +1,215 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// EnvelopeVersion is the version of the operation envelope format
+const EnvelopeVersion = 1
+
+// envelopeHeaderLen is the binary length of version, chain id, source and the limits
+const envelopeHeaderLen = 1 + 4 + 21 + 4*8 + 1
+
+// OperationEnvelope is a portable forged operation with its simulation results.
+// It is built on an online machine, signed on another one and broadcast at last.
+type OperationEnvelope struct {
+	Version      int               `json:"version"`
+	ChainID      tezos.ChainIdHash `json:"chain_id"`
+	Source       tezos.Address     `json:"source"`
+	Operation    tezos.HexBytes    `json:"operation"`
+	Fee          int64             `json:"fee"`
+	GasLimit     int64             `json:"gas_limit"`
+	StorageLimit int64             `json:"storage_limit"`
+	Burn         int64             `json:"burn"`
+	Signature    *tezos.Signature  `json:"signature,omitempty"`
+}
+
+// Op decodes the forged operation of the envelope for review
+func (e *OperationEnvelope) Op() (*codec.Op, error) {
+	op, err := codec.DecodeOp(e.Operation)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	// the envelope operation must not carry a signature
+	if !bytes.Equal(op.Bytes(), e.Operation) {
+		return nil, ErrInvalidEnvelope
+	}
+	return op, nil
+}
+
+// IsSigned returns whether the envelope has been signed
+func (e *OperationEnvelope) IsSigned() bool {
+	return e.Signature != nil && e.Signature.IsValid()
+}
+
+// MarshalBinary encodes the envelope into its binary format
+func (e OperationEnvelope) MarshalBinary() ([]byte, error) {
+	if !e.Source.IsEOA() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte(byte(e.Version))
+	buf.Write(e.ChainID.Bytes())
+	buf.Write(e.Source.Encode())
+	for _, v := range []int64{e.Fee, e.GasLimit, e.StorageLimit, e.Burn} {
+		_ = binary.Write(buf, binary.BigEndian, v)
+	}
+	if e.IsSigned() {
+		sig := e.Signature.Bytes()
+		buf.WriteByte(byte(len(sig)))
+		buf.Write(sig)
+	} else {
+		buf.WriteByte(0)
+	}
+	buf.Write(e.Operation)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the envelope from its binary format
+func (e *OperationEnvelope) UnmarshalBinary(data []byte) error {
+	if len(data) < envelopeHeaderLen || data[0] != EnvelopeVersion {
+		return ErrInvalidEnvelope
+	}
+
+	buf := bytes.NewBuffer(data)
+	v := OperationEnvelope{
+		Version: int(buf.Next(1)[0]),
+		ChainID: tezos.NewChainIdHash(buf.Next(4)),
+	}
+	if err := v.Source.Decode(buf.Next(21)); err != nil {
+		return ErrInvalidEnvelope
+	}
+	for _, p := range []*int64{&v.Fee, &v.GasLimit, &v.StorageLimit, &v.Burn} {
+		if err := binary.Read(buf, binary.BigEndian, p); err != nil {
+			return ErrInvalidEnvelope
+		}
+	}
+	if l := int(buf.Next(1)[0]); l > 0 {
+		if buf.Len() < l {
+			return ErrInvalidEnvelope
+		}
+		var sig tezos.Signature
+		if err := sig.UnmarshalBinary(buf.Next(l)); err != nil {
+			return ErrInvalidEnvelope
+		}
+		v.Signature = &sig
+	}
+	v.Operation = append([]byte{}, buf.Bytes()...)
+
+	*e = v
+	return nil
+}
+
+// BuildOperations completes and simulates a list of operations and exports it
+// as an unsigned envelope
+func (w *Wallet) BuildOperations(ops []codec.Operation) (*OperationEnvelope, error) {
+	op, opts := w.operationsOp(ops)
+	return w.build(op, opts)
+}
+
+// BuildBatchTransferXTZ completes and simulates the xtz transfers and exports
+// them as an unsigned envelope
+func (w *Wallet) BuildBatchTransferXTZ(txs []TransferXTZParam) (*OperationEnvelope, error) {
+	op, opts, err := batchTransferXTZOp(txs)
+	if err != nil {
+		return nil, err
+	}
+	return w.build(op, opts)
+}
+
+// build prepares an operation for the wallet account and exports it as an unsigned envelope
+func (w *Wallet) build(op *codec.Op, opts *rpc.CallOptions) (*OperationEnvelope, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
+	sim, err := w.prepare(context.Background(), op, opts, w.signer, w.address)
+	if err != nil {
+		return nil, err
+	}
+
+	l := op.Limits()
+	return &OperationEnvelope{
+		Version:      EnvelopeVersion,
+		ChainID:      w.chainID,
+		Source:       w.address,
+		Operation:    op.Bytes(),
+		Fee:          l.Fee,
+		GasLimit:     l.GasLimit,
+		StorageLimit: l.StorageLimit,
+		Burn:         sim.TotalCosts().Burn,
+	}, nil
+}
+
+// SignEnvelope signs an envelope with the wallet signer. It works for offline
+// wallets and makes sure every operation in the envelope is sent from the wallet
+// account with the fee and limits shown in the envelope.
+func (w *Wallet) SignEnvelope(e *OperationEnvelope) error {
+	if e.Version != EnvelopeVersion {
+		return ErrInvalidEnvelope
+	}
+	if !e.ChainID.Equal(w.chainID) {
+		return ErrWrongChainID
+	}
+	if !e.Source.Equal(w.address) {
+		return ErrSourceMismatch
+	}
+
+	op, err := e.Op()
+	if err != nil {
+		return err
+	}
+	// the forged bytes stay the same only when all sources are the wallet account
+	op.WithSource(w.address)
+	if !bytes.Equal(op.Bytes(), e.Operation) {
+		return ErrSourceMismatch
+	}
+
+	// the reviewed limits must be the ones which are signed, the burn is at most
+	// what the storage limit allows
+	l := op.Limits()
+	if e.Fee != l.Fee || e.GasLimit != l.GasLimit || e.StorageLimit != l.StorageLimit ||
+		e.Burn < 0 || e.Burn > l.StorageLimit*w.params().CostPerByte {
+		return ErrEnvelopeLimitsMismatch
+	}
+
+	sig, err := w.SignOperation(op)
+	if err != nil {
+		return err
+	}
+	e.Signature = &sig
+	return nil
+}
+
+// BroadcastEnvelope broadcasts a signed envelope and returns the operation hash
+func (w *Wallet) BroadcastEnvelope(e *OperationEnvelope) (*string, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+	if !e.IsSigned() {
+		return nil, ErrEnvelopeNotSigned
+	}
+	if !e.ChainID.Equal(w.chainID) {
+		return nil, ErrWrongChainID
+	}
+
+	op, err := e.Op()
+	if err != nil {
+		return nil, err
+	}
+	op.WithSignature(*e.Signature)
+
+	hash, err := w.rpcClient.Broadcast(context.Background(), op)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.String()
+	return &h, nil
+}
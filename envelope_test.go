@@ -0,0 +1,113 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationEnvelope(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	e := testEnvelope(w.Address(), w.chainID)
+
+	// binary round trip of an unsigned envelope
+	b, err := e.MarshalBinary()
+	assert.Nil(t, err)
+	var be OperationEnvelope
+	assert.Nil(t, be.UnmarshalBinary(b))
+	assert.EqualValues(t, *e, be)
+	assert.False(t, be.IsSigned())
+
+	assert.Nil(t, w.SignEnvelope(&be))
+	assert.True(t, be.IsSigned())
+	op, err := be.Op()
+	assert.Nil(t, err)
+	assert.Nil(t, w.PrivateKey().Public().Verify(op.Digest(), *be.Signature))
+
+	// json round trip of a signed envelope
+	j, err := json.Marshal(be)
+	assert.Nil(t, err)
+	var je OperationEnvelope
+	assert.Nil(t, json.Unmarshal(j, &je))
+	assert.EqualValues(t, be.Operation, je.Operation)
+	assert.EqualValues(t, be.Signature.String(), je.Signature.String())
+
+	// binary round trip of a signed envelope
+	b, err = be.MarshalBinary()
+	assert.Nil(t, err)
+	var se OperationEnvelope
+	assert.Nil(t, se.UnmarshalBinary(b))
+	assert.EqualValues(t, be.Signature.Bytes(), se.Signature.Bytes())
+
+	_, err = w.BroadcastEnvelope(&se)
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+}
+
+func TestSignEnvelopeValidation(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	dw, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+
+	e := testEnvelope(w.Address(), tezos.GhostnetParams.ChainId)
+	assert.EqualError(t, w.SignEnvelope(e), ErrWrongChainID.Error())
+
+	e = testEnvelope(dw.Address(), w.chainID)
+	assert.EqualError(t, w.SignEnvelope(e), ErrSourceMismatch.Error())
+
+	// the envelope source is the wallet but the operation source is not
+	e.Source = w.Address()
+	assert.EqualError(t, w.SignEnvelope(e), ErrSourceMismatch.Error())
+
+	e = testEnvelope(w.Address(), w.chainID)
+	e.Operation = e.Operation[:10]
+	assert.EqualError(t, w.SignEnvelope(e), ErrInvalidEnvelope.Error())
+
+	// the shown limits must be the signed ones
+	for _, tamper := range []func(*OperationEnvelope){
+		func(e *OperationEnvelope) { e.Fee-- },
+		func(e *OperationEnvelope) { e.GasLimit++ },
+		func(e *OperationEnvelope) { e.StorageLimit = 0 },
+		func(e *OperationEnvelope) { e.Burn = e.StorageLimit*250 + 1 },
+		func(e *OperationEnvelope) { e.Burn = -1 },
+	} {
+		e = testEnvelope(w.Address(), w.chainID)
+		tamper(e)
+		assert.EqualError(t, w.SignEnvelope(e), ErrEnvelopeLimitsMismatch.Error())
+		assert.False(t, e.IsSigned())
+	}
+	e = testEnvelope(w.Address(), w.chainID)
+	e.Burn = e.StorageLimit * 250
+	assert.Nil(t, w.SignEnvelope(e))
+
+	var be OperationEnvelope
+	assert.EqualError(t, be.UnmarshalBinary([]byte{EnvelopeVersion}), ErrInvalidEnvelope.Error())
+}
+
+func testEnvelope(source tezos.Address, chainID tezos.ChainIdHash) *OperationEnvelope {
+	op := codec.NewOp().
+		WithBranch(tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2")).
+		WithTransfer(tezos.MustParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"), 1000).
+		WithSource(source)
+	op.Contents[0].WithCounter(10)
+	op.WithLimits([]tezos.Limits{{GasLimit: 1000, StorageLimit: 100}}, 0)
+
+	l := op.Limits()
+	return &OperationEnvelope{
+		Version:      EnvelopeVersion,
+		ChainID:      chainID,
+		Source:       source,
+		Operation:    op.Bytes(),
+		Fee:          l.Fee,
+		GasLimit:     l.GasLimit,
+		StorageLimit: l.StorageLimit,
+	}
+}
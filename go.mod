@@ -7,6 +7,9 @@ require (
 	github.com/bitmark-inc/go-ed25519-hd v0.0.1
 	github.com/ethereum/go-ethereum v1.11.6
 	github.com/stretchr/testify v1.8.0
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/crypto v0.1.0
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -22,8 +25,6 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.16 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/tyler-smith/go-bip39 v1.1.0 // indirect
-	golang.org/x/crypto v0.1.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,337 @@
+package tezos
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	KeystoreVersion    = 1
+	KeystoreKDFName    = "scrypt"
+	KeystoreCipherName = "aes-256-gcm"
+	DefaultScryptN     = 1 << 17
+	DefaultScryptR     = 8
+	DefaultScryptP     = 1
+	maxScryptN         = 1 << 20
+	maxScryptR         = 32
+	maxScryptP         = 16
+	keystoreKeyLen     = 32
+	keystoreSaltLen    = 32
+	keystoreFileMode   = 0600
+)
+
+var keyTypeNames = map[tezos.KeyType]string{
+	tezos.KeyTypeEd25519:   "ed25519",
+	tezos.KeyTypeSecp256k1: "secp256k1",
+	tezos.KeyTypeP256:      "p256",
+}
+
+// Keystore is a versioned keystore which keeps a wallet seed encrypted at rest.
+// The seed is encrypted with a key derived from the password by scrypt, and the
+// metadata is authenticated along with the seed.
+type Keystore struct {
+	Version    int                 `json:"version"`
+	KDF        KeystoreKDFParam    `json:"kdf"`
+	Cipher     KeystoreCipherParam `json:"cipher"`
+	Metadata   KeystoreMetadata    `json:"metadata"`
+	Ciphertext tezos.HexBytes      `json:"ciphertext"`
+	Checksum   tezos.HexBytes      `json:"checksum"`
+}
+
+type KeystoreKDFParam struct {
+	Name string         `json:"name"`
+	N    int            `json:"n"`
+	R    int            `json:"r"`
+	P    int            `json:"p"`
+	Salt tezos.HexBytes `json:"salt"`
+}
+
+type KeystoreCipherParam struct {
+	Name  string         `json:"name"`
+	Nonce tezos.HexBytes `json:"nonce"`
+}
+
+// KeystoreMetadata is the wallet settings stored along with the encrypted seed
+type KeystoreMetadata struct {
+	Network        string    `json:"network"`
+	KeyType        string    `json:"key_type"`
+	AccountIndexes []uint    `json:"account_indexes"`
+	CreatedAt      time.Time `json:"created_at"`
+	// DerivationScheme is the derivation path scheme of the accounts, the default one if it is empty
+	DerivationScheme *DerivationScheme `json:"derivation_scheme,omitempty"`
+}
+
+// NewKeystoreMetadata creates the keystore metadata for a network and the used account indexes
+func NewKeystoreMetadata(network string, keyType tezos.KeyType, indexes ...uint) KeystoreMetadata {
+	if len(indexes) == 0 {
+		indexes = []uint{DefaultAccountIndex}
+	}
+	return KeystoreMetadata{
+		Network:        network,
+		KeyType:        keyTypeNames[keyType],
+		AccountIndexes: indexes,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// EncryptKeystore encrypts a seed with a password into a keystore
+func EncryptKeystore(seed []byte, password string, metadata KeystoreMetadata) (*Keystore, error) {
+	if _, err := parseKeyTypeName(metadata.KeyType); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	ks := &Keystore{
+		Version: KeystoreVersion,
+		KDF: KeystoreKDFParam{
+			Name: KeystoreKDFName,
+			N:    DefaultScryptN,
+			R:    DefaultScryptR,
+			P:    DefaultScryptP,
+			Salt: salt,
+		},
+		Cipher: KeystoreCipherParam{
+			Name: KeystoreCipherName,
+		},
+		Metadata: metadata,
+	}
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	ks.Cipher.Nonce = nonce
+
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	ks.Ciphertext = aead.Seal(nil, nonce, seed, ad)
+
+	ks.Checksum, err = ks.checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Decrypt verifies the keystore integrity and decrypts the seed with a password
+func (ks *Keystore) Decrypt(password string) ([]byte, error) {
+	if err := ks.validate(); err != nil {
+		return nil, err
+	}
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ks.Cipher.Nonce) != aead.NonceSize() {
+		return nil, ErrInvalidKeystore
+	}
+
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := aead.Open(nil, ks.Cipher.Nonce, ks.Ciphertext, ad)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return seed, nil
+}
+
+// ReadKeystore reads a keystore file without decrypting it
+func ReadKeystore(path string) (*Keystore, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks Keystore
+	if err := json.Unmarshal(b, &ks); err != nil {
+		return nil, ErrKeystoreCorrupted
+	}
+	if err := ks.validate(); err != nil {
+		return nil, err
+	}
+	return &ks, nil
+}
+
+// WriteKeystore writes a keystore to a file atomically
+func WriteKeystore(path string, ks *Keystore) error {
+	b, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(keystoreFileMode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// SaveKeystore encrypts a seed with a password and saves it to a keystore file
+func SaveKeystore(path string, seed []byte, password string, metadata KeystoreMetadata) error {
+	ks, err := EncryptKeystore(seed, password, metadata)
+	if err != nil {
+		return err
+	}
+	return WriteKeystore(path, ks)
+}
+
+// OpenKeystore decrypts a keystore file and creates a wallet with the stored network and
+// key type. The options are applied after the stored settings.
+func OpenKeystore(path string, password string, rpcURL string, opts ...WalletOption) (*Wallet, error) {
+	ks, err := ReadKeystore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := ks.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(seed)
+
+	keyType, err := parseKeyTypeName(ks.Metadata.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	o := []WalletOption{WithKeyType(keyType)}
+	if ks.Metadata.DerivationScheme != nil {
+		o = append(o, WithDerivationScheme(*ks.Metadata.DerivationScheme))
+	}
+	return NewWallet(seed, ks.Metadata.Network, rpcURL, append(o, opts...)...)
+}
+
+// ChangeKeystorePassword re-encrypts a keystore file with a new password
+func ChangeKeystorePassword(path string, oldPassword, newPassword string) error {
+	ks, err := ReadKeystore(path)
+	if err != nil {
+		return err
+	}
+
+	seed, err := ks.Decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+	defer wipeBytes(seed)
+
+	nks, err := EncryptKeystore(seed, newPassword, ks.Metadata)
+	if err != nil {
+		return err
+	}
+	return WriteKeystore(path, nks)
+}
+
+// validate checks the keystore version, algorithms, scrypt parameters and
+// checksum. The scrypt cost is bounded so a crafted keystore can not exhaust
+// the memory or the CPU.
+func (ks *Keystore) validate() error {
+	if ks.Version != KeystoreVersion ||
+		ks.KDF.Name != KeystoreKDFName ||
+		ks.Cipher.Name != KeystoreCipherName ||
+		ks.KDF.N <= 1 || ks.KDF.N > maxScryptN ||
+		ks.KDF.R < 1 || ks.KDF.R > maxScryptR ||
+		ks.KDF.P < 1 || ks.KDF.P > maxScryptP ||
+		len(ks.KDF.Salt) != keystoreSaltLen {
+		return ErrInvalidKeystore
+	}
+
+	sum, err := ks.checksum()
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(sum, ks.Checksum) != 1 {
+		return ErrKeystoreCorrupted
+	}
+	return nil
+}
+
+// aead derives the encryption key from the password
+func (ks *Keystore) aead(password string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), ks.KDF.Salt, ks.KDF.N, ks.KDF.R, ks.KDF.P, keystoreKeyLen)
+	if err != nil {
+		return nil, ErrInvalidKeystore
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData returns the keystore header which is authenticated by the cipher
+func (ks *Keystore) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		Version  int              `json:"version"`
+		KDF      KeystoreKDFParam `json:"kdf"`
+		Cipher   string           `json:"cipher"`
+		Metadata KeystoreMetadata `json:"metadata"`
+	}{ks.Version, ks.KDF, ks.Cipher.Name, ks.Metadata})
+}
+
+// checksum returns the digest of the keystore content. It detects accidental
+// corruption without the password, while tampering is detected by the cipher.
+func (ks *Keystore) checksum() ([]byte, error) {
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write(ad)
+	h.Write(ks.Cipher.Nonce)
+	h.Write(ks.Ciphertext)
+	return h.Sum(nil), nil
+}
+
+func parseKeyTypeName(name string) (tezos.KeyType, error) {
+	for t, n := range keyTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return tezos.KeyTypeInvalid, ErrUnsupportedKeyType
+}
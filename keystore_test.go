@@ -0,0 +1,95 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystore(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	meta := NewKeystoreMetadata("livenet", tezos.KeyTypeEd25519, 0, 1, 2)
+	assert.Nil(t, SaveKeystore(path, seed, "secret", meta))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, keystoreFileMode, info.Mode().Perm())
+
+	ks, err := ReadKeystore(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, KeystoreVersion, ks.Version)
+	assert.EqualValues(t, "livenet", ks.Metadata.Network)
+	assert.EqualValues(t, "ed25519", ks.Metadata.KeyType)
+	assert.EqualValues(t, []uint{0, 1, 2}, ks.Metadata.AccountIndexes)
+
+	s, err := ks.Decrypt("secret")
+	assert.Nil(t, err)
+	assert.EqualValues(t, seed, s)
+
+	_, err = ks.Decrypt("wrong")
+	assert.EqualError(t, err, ErrInvalidPassword.Error())
+
+	assert.EqualError(t, ChangeKeystorePassword(path, "wrong", "new secret"), ErrInvalidPassword.Error())
+	assert.Nil(t, ChangeKeystorePassword(path, "secret", "new secret"))
+
+	ks, err = ReadKeystore(path)
+	assert.Nil(t, err)
+	_, err = ks.Decrypt("secret")
+	assert.EqualError(t, err, ErrInvalidPassword.Error())
+	s, err = ks.Decrypt("new secret")
+	assert.Nil(t, err)
+	assert.EqualValues(t, seed, s)
+	assert.EqualValues(t, []uint{0, 1, 2}, ks.Metadata.AccountIndexes)
+}
+
+func TestKeystoreCorruption(t *testing.T) {
+	seed, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+
+	ks, err := EncryptKeystore(seed, "secret", NewKeystoreMetadata("testnet", tezos.KeyTypeSecp256k1))
+	assert.Nil(t, err)
+
+	ks.Ciphertext[0] ^= 0xff
+	_, err = ks.Decrypt("secret")
+	assert.EqualError(t, err, ErrKeystoreCorrupted.Error())
+	ks.Ciphertext[0] ^= 0xff
+
+	ks.Metadata.Network = "livenet"
+	_, err = ks.Decrypt("secret")
+	assert.EqualError(t, err, ErrKeystoreCorrupted.Error())
+	ks.Metadata.Network = "testnet"
+
+	ks.Version = 2
+	_, err = ks.Decrypt("secret")
+	assert.EqualError(t, err, ErrInvalidKeystore.Error())
+	ks.Version = KeystoreVersion
+
+	// the scrypt parameters are bounded
+	kdf := ks.KDF
+	for _, tamper := range []func(){
+		func() { ks.KDF.N = maxScryptN << 1 },
+		func() { ks.KDF.R = maxScryptR + 1 },
+		func() { ks.KDF.R = 0 },
+		func() { ks.KDF.P = maxScryptP + 1 },
+		func() { ks.KDF.P = 0 },
+		func() { ks.KDF.Salt = ks.KDF.Salt[:keystoreSaltLen-1] },
+	} {
+		tamper()
+		_, err = ks.Decrypt("secret")
+		assert.EqualError(t, err, ErrInvalidKeystore.Error())
+		ks.KDF = kdf
+	}
+
+	path := filepath.Join(t.TempDir(), "vault.json")
+	assert.Nil(t, os.WriteFile(path, []byte("{\"version\":"), 0600))
+	_, err = ReadKeystore(path)
+	assert.EqualError(t, err, ErrKeystoreCorrupted.Error())
+
+	_, err = EncryptKeystore(seed, "secret", NewKeystoreMetadata("testnet", tezos.KeyTypeBls12_381))
+	assert.EqualError(t, err, ErrUnsupportedKeyType.Error())
+}
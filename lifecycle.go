@@ -0,0 +1,79 @@
+package tezos
+
+import (
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// WithDerivedKeyOnly keeps only the key of the default account and wipes the
+// master key right after the derivation. It fits single-account services, and
+// the wallet can not derive other accounts.
+func WithDerivedKeyOnly() WalletOption {
+	return func(o *walletOptions) {
+		o.derivedKeyOnly = true
+	}
+}
+
+// WithoutKeyExport disables the raw private key export of the wallet and all
+// the accounts derived from it
+func WithoutKeyExport() WalletOption {
+	return func(o *walletOptions) {
+		o.keyExport = false
+	}
+}
+
+// ExportPrivateKey returns a copy of the raw private key of the wallet account
+func (w *Wallet) ExportPrivateKey() (tezos.PrivateKey, error) {
+	if !w.privateKey.IsValid() {
+		return tezos.PrivateKey{}, ErrPrivateKeyUnavailable
+	}
+	if !w.keyExport {
+		return tezos.PrivateKey{}, ErrKeyExportDisabled
+	}
+	return tezos.PrivateKey{
+		Type: w.privateKey.Type,
+		Data: append([]byte{}, w.privateKey.Data...),
+	}, nil
+}
+
+// Wipe zeroes the key material held by the wallet. The wallet refuses to sign
+// afterwards. The master key is shared with the derived accounts, so it is only
+// wiped by the wallet which created it, and the derived accounts can not derive
+// any other account afterwards.
+func (w *Wallet) Wipe() {
+	if ks, ok := w.signer.(*KeySigner); ok {
+		ks.Wipe()
+	}
+	wipeBytes(w.privateKey.Data)
+	w.privateKey = tezos.PrivateKey{}
+
+	if w.masterKey != nil && w.ownsMaster {
+		w.masterKey.wipe()
+	}
+	w.masterKey = nil
+}
+
+// Close wipes the key material of the wallet. It implements io.Closer.
+func (w *Wallet) Close() error {
+	w.Wipe()
+	return nil
+}
+
+// Close wipes the key material of all the accounts and the root wallet
+func (m *MultiAccountWallet) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, w := range m.accounts {
+		w.Wipe()
+	}
+	m.root.Wipe()
+	m.accounts = map[uint]*Wallet{}
+	return nil
+}
+
+// wipeBytes zeroes a byte slice in place
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,102 @@
+package tezos
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletWipe(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	key := w.privateKey
+	dw, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+
+	// wiping a derived account keeps the master key of the root wallet
+	assert.Nil(t, dw.Close())
+	_, err = dw.signMessage([]byte("message"))
+	assert.EqualError(t, err, ErrSignFailed.Error())
+	_, err = dw.DeriveAccount(2)
+	assert.EqualError(t, err, ErrMasterKeyUnavailable.Error())
+	_, err = w.DeriveAccount(2)
+	assert.Nil(t, err)
+
+	w.Wipe()
+	assert.EqualValues(t, make([]byte, len(key.Data)), key.Data)
+	assert.False(t, w.PrivateKey().IsValid())
+	_, err = w.ExportPrivateKey()
+	assert.EqualError(t, err, ErrPrivateKeyUnavailable.Error())
+	_, err = w.DeriveSigner(1)
+	assert.EqualError(t, err, ErrMasterKeyUnavailable.Error())
+	_, err = w.Signer().SignBytes(context.Background(), w.Address(), []byte("message"))
+	assert.EqualError(t, err, ErrKeyWiped.Error())
+	_, err = w.Signer().ListAddresses(context.Background())
+	assert.EqualError(t, err, ErrKeyWiped.Error())
+}
+
+func TestMasterKeyWipe(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	for _, kt := range []tezos.KeyType{tezos.KeyTypeEd25519, tezos.KeyTypeSecp256k1} {
+		w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU", WithKeyType(kt))
+		assert.Nil(t, err)
+		dw, err := w.DeriveAccount(1)
+		assert.Nil(t, err)
+
+		// the derived accounts can not derive from a wiped master key
+		mk := w.masterKey
+		w.Wipe()
+		assert.True(t, dw.PrivateKey().IsValid())
+		_, err = dw.DeriveAccount(2)
+		assert.EqualError(t, err, ErrKeyWiped.Error())
+
+		// the key and its chain code are zeroed
+		switch k := mk.(type) {
+		case *ed25519Key:
+			assert.EqualValues(t, make([]byte, 32), k.Key)
+			assert.EqualValues(t, make([]byte, 32), k.ChainCode)
+		case *extendedKey:
+			assert.EqualValues(t, make([]byte, 32), k.key)
+			assert.EqualValues(t, make([]byte, 32), k.chainCode)
+		}
+	}
+}
+
+func TestWithDerivedKeyOnly(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU", WithDerivedKeyOnly())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", w.Account())
+
+	_, err = w.DeriveAccount(1)
+	assert.EqualError(t, err, ErrMasterKeyUnavailable.Error())
+	_, err = w.signMessage([]byte("message"))
+	assert.Nil(t, err)
+}
+
+func TestWithoutKeyExport(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	key, err := w.ExportPrivateKey()
+	assert.Nil(t, err)
+	assert.EqualValues(t, w.Address(), key.Address())
+
+	w, err = NewOfflineWallet(s, "NetXdQprcVkpaWU", WithoutKeyExport())
+	assert.Nil(t, err)
+	assert.False(t, w.PrivateKey().IsValid())
+	_, err = w.ExportPrivateKey()
+	assert.EqualError(t, err, ErrKeyExportDisabled.Error())
+
+	dw, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+	_, err = dw.ExportPrivateKey()
+	assert.EqualError(t, err, ErrKeyExportDisabled.Error())
+	_, err = dw.signMessage([]byte("message"))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,759 @@
+package tezos
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+var (
+	primType      = reflect.TypeOf(micheline.Prim{})
+	timeType      = reflect.TypeOf(time.Time{})
+	bigIntType    = reflect.TypeOf(big.Int{})
+	zType         = reflect.TypeOf(tezos.Z{})
+	nType         = reflect.TypeOf(tezos.N(0))
+	addressType   = reflect.TypeOf(tezos.Address{})
+	keyType       = reflect.TypeOf(tezos.Key{})
+	signatureType = reflect.TypeOf(tezos.Signature{})
+	chainIDType   = reflect.TypeOf(tezos.ChainIdHash{})
+	hexBytesType  = reflect.TypeOf(tezos.HexBytes{})
+)
+
+// michelsonType is a Michelson type parsed from a struct tag like map(nat,address)
+type michelsonType struct {
+	name string
+	args []*michelsonType
+}
+
+// arg returns the type of an argument, nil when the tag does not define it
+func (t *michelsonType) arg(i int) *michelsonType {
+	if t == nil || i >= len(t.args) {
+		return nil
+	}
+	return t.args[i]
+}
+
+// prim returns the Michelson type of a type expression, of which the args of
+// struct pairs are not known
+func (t *michelsonType) prim() micheline.Prim {
+	if t == nil {
+		return micheline.InvalidPrim
+	}
+	op, err := micheline.ParseOpCode(t.name)
+	if err != nil {
+		return micheline.InvalidPrim
+	}
+	p := micheline.NewPrim(op)
+	for _, arg := range t.args {
+		p.Args = append(p.Args, arg.prim())
+	}
+	return p
+}
+
+// MarshalMichelson converts a Go value into a Michelson value in the optimized
+// form. The Michelson type of a struct field is given by its michelson tag, like
+// `michelson:"nat"` or `michelson:"map(string,bytes)"`, and a field tagged "-" is
+// skipped. A field with the omitempty option, like `michelson:"nat,omitempty"`, is
+// left out of the pair when it is empty. Without a tag the type follows the Go type: structs are right comb
+// pairs of their fields in order, pointers options, slices lists, maps maps,
+// strings strings, signed integers, *big.Int and tezos.Z ints, unsigned integers
+// and tezos.N nats, []byte and tezos.HexBytes bytes, time.Time timestamps, and
+// tezos.Address, tezos.Key, tezos.Signature and tezos.ChainIdHash their types.
+// A value tagged bytes which is not a byte slice uses its binary encoding.
+// An or is a struct tagged "or" whose two pointer fields are the left and the
+// right side, of which one is set. A big_map is a Go map, or its id as integer.
+// A micheline.Prim is used as it is.
+func MarshalMichelson(v interface{}) (micheline.Prim, error) {
+	return marshalMichelson(reflect.ValueOf(v), nil)
+}
+
+// UnmarshalMichelson stores a Michelson value into the Go value v points to. It
+// is the inverse of MarshalMichelson and accepts both the optimized and the
+// readable form of values. Fields with the omitempty option are only read when
+// the value does not fit the struct with them.
+func UnmarshalMichelson(p micheline.Prim, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: %T is not a pointer", ErrInvalidMichelsonValue, v)
+	}
+	return unmarshalMichelson(p, rv.Elem(), nil)
+}
+
+func marshalMichelson(rv reflect.Value, t *michelsonType) (micheline.Prim, error) {
+	if !rv.IsValid() {
+		if t != nil && t.name == "option" {
+			return micheline.NewPrim(micheline.D_NONE), nil
+		}
+		return micheline.NewPrim(micheline.D_UNIT), nil
+	}
+	if rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return marshalMichelson(reflect.Value{}, t)
+		}
+		return marshalMichelson(rv.Elem(), t)
+	}
+	if rv.Type() == primType {
+		return rv.Interface().(micheline.Prim), nil
+	}
+	if t == nil || t.name == "" {
+		var err error
+		if t, err = inferMichelsonType(rv.Type()); err != nil {
+			return micheline.InvalidPrim, err
+		}
+	}
+
+	switch t.name {
+	case "int", "nat", "mutez":
+		i, err := reflectInt(rv)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		if t.name != "int" && i.Sign() < 0 {
+			return micheline.InvalidPrim, fmt.Errorf("%w: %s is not a valid %s", ErrInvalidMichelsonValue, i, t.name)
+		}
+		return micheline.NewBig(i), nil
+
+	case "timestamp":
+		switch {
+		case rv.Type() == timeType:
+			return micheline.NewInt64(rv.Interface().(time.Time).Unix()), nil
+		case rv.Kind() == reflect.String:
+			ts, err := time.Parse(time.RFC3339, rv.String())
+			if err != nil {
+				return micheline.InvalidPrim, reflectError(t, rv)
+			}
+			return micheline.NewInt64(ts.Unix()), nil
+		}
+		i, err := reflectInt(rv)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return micheline.NewBig(i), nil
+
+	case "string":
+		if rv.Kind() != reflect.String {
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		return micheline.NewString(rv.String()), nil
+
+	case "bytes":
+		switch {
+		case rv.Kind() == reflect.String:
+			b, err := hex.DecodeString(strings.TrimPrefix(rv.String(), "0x"))
+			if err != nil {
+				return micheline.InvalidPrim, reflectError(t, rv)
+			}
+			return micheline.NewBytes(b), nil
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			return micheline.NewBytes(rv.Bytes()), nil
+		}
+		if m, ok := rv.Interface().(encoding.BinaryMarshaler); ok {
+			b, err := m.MarshalBinary()
+			if err != nil {
+				return micheline.InvalidPrim, fmt.Errorf("%w: %v", ErrInvalidMichelsonValue, err)
+			}
+			return micheline.NewBytes(b), nil
+		}
+		return micheline.InvalidPrim, reflectError(t, rv)
+
+	case "bool":
+		if rv.Kind() != reflect.Bool {
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		if rv.Bool() {
+			return micheline.NewPrim(micheline.D_TRUE), nil
+		}
+		return micheline.NewPrim(micheline.D_FALSE), nil
+
+	case "unit":
+		return micheline.NewPrim(micheline.D_UNIT), nil
+
+	case "address", "contract", "key_hash", "key", "signature", "chain_id":
+		return encodeValue(micheline.NewPrim(michelsonOpCode(t.name)), rv.Interface())
+
+	case "option":
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return micheline.NewPrim(micheline.D_NONE), nil
+			}
+			rv = rv.Elem()
+		}
+		p, err := marshalMichelson(rv, t.arg(0))
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return micheline.NewCode(micheline.D_SOME, p), nil
+
+	case "or":
+		if rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct || rv.NumField() != 2 {
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		l, r := rv.Field(0), rv.Field(1)
+		switch {
+		case l.Kind() == reflect.Pointer && !l.IsNil() && (r.Kind() != reflect.Pointer || r.IsNil()):
+			p, err := marshalMichelson(l.Elem(), t.arg(0))
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			return micheline.NewCode(micheline.D_LEFT, p), nil
+		case r.Kind() == reflect.Pointer && !r.IsNil() && (l.Kind() != reflect.Pointer || l.IsNil()):
+			p, err := marshalMichelson(r.Elem(), t.arg(1))
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			return micheline.NewCode(micheline.D_RIGHT, p), nil
+		}
+		return micheline.InvalidPrim, fmt.Errorf("%w: exactly one side of %s must be set", ErrInvalidMichelsonValue, rv.Type())
+
+	case "pair":
+		if rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		fields, types, _, err := structFields(rv, t, reflect.Value.IsZero)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		if len(fields) == 0 {
+			return micheline.NewPrim(micheline.D_UNIT), nil
+		}
+		// build the right comb from the last field
+		p, err := marshalMichelson(fields[len(fields)-1], types[len(fields)-1])
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		for i := len(fields) - 2; i >= 0; i-- {
+			l, err := marshalMichelson(fields[i], types[i])
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			p = micheline.NewPair(l, p)
+		}
+		return p, nil
+
+	case "list", "set":
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		seq := micheline.NewSeq()
+		for i := 0; i < rv.Len(); i++ {
+			p, err := marshalMichelson(rv.Index(i), t.arg(0))
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			seq.Args = append(seq.Args, p)
+		}
+		if t.name == "set" {
+			if err := sortValues(t.arg(0).prim(), seq.Args, func(p micheline.Prim) micheline.Prim { return p }); err != nil {
+				return micheline.InvalidPrim, err
+			}
+		}
+		return seq, nil
+
+	case "map", "big_map":
+		if rv.Kind() != reflect.Map {
+			if t.name == "big_map" {
+				if i, err := reflectInt(rv); err == nil {
+					return micheline.NewBig(i), nil
+				}
+			}
+			return micheline.InvalidPrim, reflectError(t, rv)
+		}
+		seq := micheline.NewSeq()
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := marshalMichelson(iter.Key(), t.arg(0))
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			v, err := marshalMichelson(iter.Value(), t.arg(1))
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			seq.Args = append(seq.Args, micheline.NewMapElem(k, v))
+		}
+		if err := sortValues(t.arg(0).prim(), seq.Args, func(p micheline.Prim) micheline.Prim { return p.Args[0] }); err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return seq, nil
+	}
+
+	return micheline.InvalidPrim, fmt.Errorf("%w: %s", ErrUnsupportedMichelsonType, t.name)
+}
+
+func unmarshalMichelson(p micheline.Prim, rv reflect.Value, t *michelsonType) error {
+	if rv.Type() == primType {
+		rv.Set(reflect.ValueOf(p))
+		return nil
+	}
+	if t == nil || t.name == "" {
+		var err error
+		if t, err = inferMichelsonType(rv.Type()); err != nil {
+			return err
+		}
+	}
+
+	switch t.name {
+	case "int", "nat", "mutez":
+		if p.Type != micheline.PrimInt {
+			return primError(t, p)
+		}
+		return setInt(rv, p.Int, t, p)
+
+	case "timestamp":
+		var ts time.Time
+		switch p.Type {
+		case micheline.PrimInt:
+			if !p.Int.IsInt64() {
+				return primError(t, p)
+			}
+			ts = time.Unix(p.Int.Int64(), 0).UTC()
+		case micheline.PrimString:
+			var err error
+			if ts, err = time.Parse(time.RFC3339, p.String); err != nil {
+				return primError(t, p)
+			}
+		default:
+			return primError(t, p)
+		}
+		switch {
+		case rv.Type() == timeType:
+			rv.Set(reflect.ValueOf(ts))
+			return nil
+		case rv.Kind() == reflect.String:
+			rv.SetString(ts.Format(time.RFC3339))
+			return nil
+		}
+		return setInt(rv, big.NewInt(ts.Unix()), t, p)
+
+	case "string":
+		if p.Type != micheline.PrimString || rv.Kind() != reflect.String {
+			return primError(t, p)
+		}
+		rv.SetString(p.String)
+		return nil
+
+	case "bytes":
+		if p.Type != micheline.PrimBytes {
+			return primError(t, p)
+		}
+		switch {
+		case rv.Kind() == reflect.String:
+			rv.SetString(hex.EncodeToString(p.Bytes))
+			return nil
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			b := make([]byte, len(p.Bytes))
+			copy(b, p.Bytes)
+			rv.SetBytes(b)
+			return nil
+		}
+		if rv.CanAddr() {
+			if u, ok := rv.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+				if err := u.UnmarshalBinary(p.Bytes); err != nil {
+					return fmt.Errorf("%w: %v", ErrInvalidMichelsonValue, err)
+				}
+				return nil
+			}
+		}
+		return primError(t, p)
+
+	case "bool":
+		if rv.Kind() != reflect.Bool {
+			return primError(t, p)
+		}
+		switch p.OpCode {
+		case micheline.D_TRUE:
+			rv.SetBool(true)
+		case micheline.D_FALSE:
+			rv.SetBool(false)
+		default:
+			return primError(t, p)
+		}
+		return nil
+
+	case "unit":
+		return nil
+
+	case "address", "contract", "key_hash", "key", "signature", "chain_id":
+		d, err := decodeValue(micheline.NewPrim(michelsonOpCode(t.name)), p)
+		if err != nil {
+			return err
+		}
+		s := d.(string)
+		var v interface{}
+		switch rv.Type() {
+		case addressType:
+			addr, _, err := valueAddress(s)
+			if err != nil {
+				return primError(t, p)
+			}
+			v = addr
+		case keyType:
+			if v, err = tezos.ParseKey(s); err != nil {
+				return primError(t, p)
+			}
+		case signatureType:
+			if v, err = tezos.ParseSignature(s); err != nil {
+				return primError(t, p)
+			}
+		case chainIDType:
+			if v, err = tezos.ParseChainIdHash(s); err != nil {
+				return primError(t, p)
+			}
+		default:
+			if rv.Kind() != reflect.String {
+				return primError(t, p)
+			}
+			rv.SetString(s)
+			return nil
+		}
+		rv.Set(reflect.ValueOf(v))
+		return nil
+
+	case "option":
+		switch p.OpCode {
+		case micheline.D_NONE:
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		case micheline.D_SOME:
+			if len(p.Args) != 1 {
+				return primError(t, p)
+			}
+			if rv.Kind() == reflect.Pointer {
+				e := reflect.New(rv.Type().Elem())
+				if err := unmarshalMichelson(p.Args[0], e.Elem(), t.arg(0)); err != nil {
+					return err
+				}
+				rv.Set(e)
+				return nil
+			}
+			return unmarshalMichelson(p.Args[0], rv, t.arg(0))
+		}
+		return primError(t, p)
+
+	case "or":
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct || rv.NumField() != 2 || len(p.Args) != 1 {
+			return primError(t, p)
+		}
+		side := 0
+		switch p.OpCode {
+		case micheline.D_LEFT:
+		case micheline.D_RIGHT:
+			side = 1
+		default:
+			return primError(t, p)
+		}
+		rv.Set(reflect.Zero(rv.Type()))
+		f := rv.Field(side)
+		if f.Kind() != reflect.Pointer {
+			return primError(t, p)
+		}
+		e := reflect.New(f.Type().Elem())
+		if err := unmarshalMichelson(p.Args[0], e.Elem(), t.arg(side)); err != nil {
+			return err
+		}
+		f.Set(e)
+		return nil
+
+	case "pair":
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return primError(t, p)
+		}
+		fields, types, optional, err := structFields(rv, t, nil)
+		if err != nil {
+			return err
+		}
+		err = unmarshalFields(p, fields, types, t)
+		if err == nil || !optional {
+			return err
+		}
+		// read the value again without the omitempty fields
+		rv.Set(reflect.Zero(rv.Type()))
+		fields, types, _, err = structFields(rv, t, func(reflect.Value) bool { return true })
+		if err != nil {
+			return err
+		}
+		return unmarshalFields(p, fields, types, t)
+
+	case "list", "set":
+		if p.Type != micheline.PrimSequence || rv.Kind() != reflect.Slice {
+			return primError(t, p)
+		}
+		s := reflect.MakeSlice(rv.Type(), len(p.Args), len(p.Args))
+		for i, e := range p.Args {
+			if err := unmarshalMichelson(e, s.Index(i), t.arg(0)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+		return nil
+
+	case "map", "big_map":
+		if t.name == "big_map" && p.Type == micheline.PrimInt {
+			return setInt(rv, p.Int, t, p)
+		}
+		if p.Type != micheline.PrimSequence || rv.Kind() != reflect.Map {
+			return primError(t, p)
+		}
+		m := reflect.MakeMapWithSize(rv.Type(), len(p.Args))
+		for _, e := range p.Args {
+			if e.OpCode != micheline.D_ELT || len(e.Args) != 2 {
+				return primError(t, p)
+			}
+			k := reflect.New(rv.Type().Key()).Elem()
+			if err := unmarshalMichelson(e.Args[0], k, t.arg(0)); err != nil {
+				return err
+			}
+			v := reflect.New(rv.Type().Elem()).Elem()
+			if err := unmarshalMichelson(e.Args[1], v, t.arg(1)); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		rv.Set(m)
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedMichelsonType, t.name)
+}
+
+// inferMichelsonType returns the Michelson type of a Go type without a tag
+func inferMichelsonType(typ reflect.Type) (*michelsonType, error) {
+	switch typ {
+	case timeType:
+		return &michelsonType{name: "timestamp"}, nil
+	case bigIntType, zType:
+		return &michelsonType{name: "int"}, nil
+	case nType:
+		return &michelsonType{name: "nat"}, nil
+	case addressType:
+		return &michelsonType{name: "address"}, nil
+	case keyType:
+		return &michelsonType{name: "key"}, nil
+	case signatureType:
+		return &michelsonType{name: "signature"}, nil
+	case chainIDType:
+		return &michelsonType{name: "chain_id"}, nil
+	case hexBytesType:
+		return &michelsonType{name: "bytes"}, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &michelsonType{name: "bool"}, nil
+	case reflect.String:
+		return &michelsonType{name: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &michelsonType{name: "int"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &michelsonType{name: "nat"}, nil
+	case reflect.Pointer:
+		if typ.Elem() == bigIntType {
+			return &michelsonType{name: "int"}, nil
+		}
+		return &michelsonType{name: "option"}, nil
+	case reflect.Struct:
+		return &michelsonType{name: "pair"}, nil
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &michelsonType{name: "bytes"}, nil
+		}
+		return &michelsonType{name: "list"}, nil
+	case reflect.Map:
+		return &michelsonType{name: "map"}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMichelsonType, typ)
+}
+
+// unmarshalFields stores the values of a right comb into struct fields
+func unmarshalFields(p micheline.Prim, fields []reflect.Value, types []*michelsonType, t *michelsonType) error {
+	for i, f := range fields {
+		if i == len(fields)-1 {
+			return unmarshalMichelson(p, f, types[i])
+		}
+		// comb values with more than two args are handled as right combs
+		if (p.OpCode == micheline.D_PAIR || p.Type == micheline.PrimSequence) && len(p.Args) > 2 {
+			p = micheline.NewPair(p.Args[0], micheline.NewCombPair(p.Args[1:]...))
+		}
+		if len(p.Args) != 2 || (p.OpCode != micheline.D_PAIR && p.Type != micheline.PrimSequence) {
+			return primError(t, p)
+		}
+		if err := unmarshalMichelson(p.Args[0], f, types[i]); err != nil {
+			return err
+		}
+		p = p.Args[1]
+	}
+	return nil
+}
+
+// structFields returns the fields of a struct which are part of its pair and
+// their types from the michelson tags. Positional args of the pair type are
+// used for fields without a tag. Fields with the omitempty option are left out
+// when omit returns true for them, and optional reports whether there are any.
+func structFields(rv reflect.Value, t *michelsonType, omit func(reflect.Value) bool) (fields []reflect.Value, types []*michelsonType, optional bool, err error) {
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("michelson")
+		if tag == "-" {
+			continue
+		}
+		tag, omitEmpty := strings.CutSuffix(tag, ",omitempty")
+		if omitEmpty {
+			optional = true
+			if omit != nil && omit(rv.Field(i)) {
+				continue
+			}
+		}
+		ft := t.arg(len(fields))
+		if tag != "" {
+			if ft, err = parseMichelsonTag(tag); err != nil {
+				return nil, nil, false, err
+			}
+		}
+		fields = append(fields, rv.Field(i))
+		types = append(types, ft)
+	}
+	return fields, types, optional, nil
+}
+
+// parseMichelsonTag parses a type expression like map(nat,option(address))
+func parseMichelsonTag(tag string) (*michelsonType, error) {
+	t, rest, err := parseMichelsonExpr(strings.ReplaceAll(tag, " ", ""))
+	if err != nil || rest != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMichelsonTag, tag)
+	}
+	return t, nil
+}
+
+func parseMichelsonExpr(s string) (*michelsonType, string, error) {
+	i := strings.IndexAny(s, "(,)")
+	if i < 0 {
+		i = len(s)
+	}
+	t := &michelsonType{name: s[:i]}
+	if t.name == "" {
+		return nil, "", ErrInvalidMichelsonTag
+	}
+	s = s[i:]
+	if !strings.HasPrefix(s, "(") {
+		return t, s, nil
+	}
+	s = s[1:]
+	for {
+		arg, rest, err := parseMichelsonExpr(s)
+		if err != nil {
+			return nil, "", err
+		}
+		t.args = append(t.args, arg)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			s = rest[1:]
+		case strings.HasPrefix(rest, ")"):
+			return t, rest[1:], nil
+		default:
+			return nil, "", ErrInvalidMichelsonTag
+		}
+	}
+}
+
+// michelsonOpCode returns the type opcode of a scalar type name
+func michelsonOpCode(name string) micheline.OpCode {
+	switch name {
+	case "address":
+		return micheline.T_ADDRESS
+	case "contract":
+		return micheline.T_CONTRACT
+	case "key_hash":
+		return micheline.T_KEY_HASH
+	case "key":
+		return micheline.T_KEY
+	case "signature":
+		return micheline.T_SIGNATURE
+	}
+	return micheline.T_CHAIN_ID
+}
+
+// reflectInt converts an integer, a big integer or a decimal string into an integer
+func reflectInt(rv reflect.Value) (*big.Int, error) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return valueInt(rv.Interface())
+}
+
+// setInt stores an integer into an integer, a big integer or a decimal string
+func setInt(rv reflect.Value, i *big.Int, t *michelsonType, p micheline.Prim) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !i.IsInt64() || rv.OverflowInt(i.Int64()) {
+			return primError(t, p)
+		}
+		rv.SetInt(i.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !i.IsUint64() || rv.OverflowUint(i.Uint64()) {
+			return primError(t, p)
+		}
+		rv.SetUint(i.Uint64())
+		return nil
+	case reflect.String:
+		rv.SetString(i.String())
+		return nil
+	}
+	switch rv.Type() {
+	case bigIntType:
+		rv.Set(reflect.ValueOf(*new(big.Int).Set(i)))
+		return nil
+	case reflect.PointerTo(bigIntType):
+		rv.Set(reflect.ValueOf(new(big.Int).Set(i)))
+		return nil
+	case zType:
+		rv.Set(reflect.ValueOf(tezos.NewBigZ(i)))
+		return nil
+	case nType:
+		if !i.IsInt64() || i.Sign() < 0 {
+			return primError(t, p)
+		}
+		rv.Set(reflect.ValueOf(tezos.N(i.Int64())))
+		return nil
+	}
+	return primError(t, p)
+}
+
+func reflectError(t *michelsonType, rv reflect.Value) error {
+	return fmt.Errorf("%w: %s is not a valid %s", ErrInvalidMichelsonValue, rv.Type(), t.name)
+}
+
+func primError(t *michelsonType, p micheline.Prim) error {
+	return fmt.Errorf("%w: %s is not a valid %s", ErrInvalidMichelsonValue, p.Dump(), t.name)
+}
@@ -0,0 +1,196 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+type testOperator struct {
+	Owner    tezos.Address
+	Operator string `michelson:"address"`
+	TokenID  uint64
+}
+
+type testUpdate struct {
+	Add    *testOperator
+	Remove *testOperator
+}
+
+type testStorage struct {
+	Admin     tezos.Address
+	Paused    bool
+	Ledger    map[string]*big.Int `michelson:"big_map(string,nat)"`
+	Trustees  []string            `michelson:"set(address)"`
+	Metadata  map[string][]byte
+	Expiry    *time.Time
+	Update    testUpdate `michelson:"or"`
+	Note      string     `michelson:"-"`
+	BigMapRef int64      `michelson:"big_map(nat,nat)"`
+	Raw       micheline.Prim
+}
+
+func TestMarshalMichelson(t *testing.T) {
+	admin, _ := tezos.ParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	expiry := time.Unix(1700000000, 0).UTC()
+	s := testStorage{
+		Admin:    admin,
+		Paused:   true,
+		Ledger:   map[string]*big.Int{"b": big.NewInt(2), "a": big.NewInt(1)},
+		Trustees: []string{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"},
+		Metadata: map[string][]byte{"": []byte("ipfs://")},
+		Expiry:   &expiry,
+		Update: testUpdate{Remove: &testOperator{
+			Owner:    admin,
+			Operator: "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX",
+			TokenID:  7,
+		}},
+		Note:      "skipped",
+		BigMapRef: 42,
+		Raw:       micheline.NewString("raw"),
+	}
+
+	p, err := MarshalMichelson(s)
+	assert.Nil(t, err)
+
+	// the struct is a right comb pair matching the value built from its type
+	typ := micheline.MustParseType(`{"prim":"pair","args":[{"prim":"address"},{"prim":"bool"},` +
+		`{"prim":"big_map","args":[{"prim":"string"},{"prim":"nat"}]},{"prim":"set","args":[{"prim":"address"}]},` +
+		`{"prim":"map","args":[{"prim":"string"},{"prim":"bytes"}]},{"prim":"option","args":[{"prim":"timestamp"}]},` +
+		`{"prim":"or","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"address"},{"prim":"nat"}]},{"prim":"pair","args":[{"prim":"address"},{"prim":"address"},{"prim":"nat"}]}]},` +
+		`{"prim":"int"},{"prim":"string"}]}`)
+	expected, err := EncodeValue(typ, []interface{}{
+		"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+		true,
+		map[string]interface{}{"a": 1, "b": 2},
+		[]interface{}{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"},
+		map[string]interface{}{"": "697066733a2f2f"},
+		expiry.Unix(),
+		map[string]interface{}{"Right": []interface{}{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", 7}},
+		42,
+		"raw",
+	})
+	assert.Nil(t, err)
+	pb, _ := p.MarshalBinary()
+	eb, _ := expected.MarshalBinary()
+	assert.EqualValues(t, eb, pb)
+
+	var d testStorage
+	assert.Nil(t, UnmarshalMichelson(p, &d))
+	s.Note = ""
+	assert.EqualValues(t, s, d)
+
+	// readable values and flat comb pairs are accepted
+	var o testOperator
+	assert.Nil(t, UnmarshalMichelson(micheline.NewCombPair(
+		micheline.NewString("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"),
+		micheline.NewString("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"),
+		micheline.NewInt64(7),
+	), &o))
+	assert.EqualValues(t, *s.Update.Remove, o)
+
+	var none struct {
+		Expiry *time.Time
+		Count  tezos.N
+	}
+	assert.Nil(t, UnmarshalMichelson(micheline.NewPair(micheline.NewPrim(micheline.D_NONE), micheline.NewInt64(3)), &none))
+	assert.Nil(t, none.Expiry)
+	assert.EqualValues(t, 3, none.Count)
+
+	_, err = MarshalMichelson(struct {
+		Amount int `michelson:"nat"`
+	}{-1})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+	_, err = MarshalMichelson(struct {
+		Amount int `michelson:"map(nat"`
+	}{1})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonTag)
+	_, err = MarshalMichelson(testUpdate{})
+	assert.Nil(t, err)
+	_, err = MarshalMichelson(struct {
+		Update testUpdate `michelson:"or"`
+	}{})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+	_, err = MarshalMichelson(struct{ F func() }{})
+	assert.ErrorIs(t, err, ErrUnsupportedMichelsonType)
+
+	assert.ErrorIs(t, UnmarshalMichelson(micheline.NewString("a"), &o), ErrInvalidMichelsonValue)
+	assert.ErrorIs(t, UnmarshalMichelson(micheline.NewInt64(1), o), ErrInvalidMichelsonValue)
+	var small uint8
+	assert.ErrorIs(t, UnmarshalMichelson(micheline.NewInt64(256), &small), ErrInvalidMichelsonValue)
+}
+
+func TestMarshalMichelsonOmitEmpty(t *testing.T) {
+	type transfer struct {
+		To        tezos.Address
+		Nonce     *big.Int        `michelson:"nat,omitempty"`
+		Signature tezos.Signature `michelson:"bytes"`
+	}
+	to, _ := tezos.ParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	sig := tezos.Signature{Type: tezos.SignatureTypeEd25519, Data: make([]byte, 64)}
+	sig.Data[0] = 1
+
+	p, err := MarshalMichelson(transfer{To: to, Signature: sig})
+	assert.Nil(t, err)
+	assert.EqualValues(t, micheline.NewPair(
+		micheline.NewBytes(to.EncodePadded()),
+		micheline.NewBytes(sig.Bytes()),
+	), p)
+
+	var d transfer
+	assert.Nil(t, UnmarshalMichelson(p, &d))
+	assert.EqualValues(t, transfer{To: to, Signature: sig}, d)
+
+	p, err = MarshalMichelson(transfer{To: to, Nonce: big.NewInt(3), Signature: sig})
+	assert.Nil(t, err)
+	assert.EqualValues(t, micheline.NewPair(
+		micheline.NewBytes(to.EncodePadded()),
+		micheline.NewPair(micheline.NewInt64(3), micheline.NewBytes(sig.Bytes())),
+	), p)
+
+	d = transfer{}
+	assert.Nil(t, UnmarshalMichelson(p, &d))
+	assert.EqualValues(t, transfer{To: to, Nonce: big.NewInt(3), Signature: sig}, d)
+
+	assert.ErrorIs(t, UnmarshalMichelson(micheline.NewPair(
+		micheline.NewBytes(to.EncodePadded()),
+		micheline.NewBytes([]byte{1}),
+	), &d), ErrInvalidMichelsonValue)
+}
+
+func TestMarshalMichelsonCompositeKeys(t *testing.T) {
+	type operatorKey struct {
+		Owner   tezos.Address
+		TokenID uint64
+	}
+	tz1b, _ := tezos.ParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	tz1T, _ := tezos.ParseAddress("tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd")
+	operators := map[operatorKey]uint64{
+		{Owner: tz1b, TokenID: 1}: 1,
+		{Owner: tz1T, TokenID: 2}: 2,
+		{Owner: tz1T, TokenID: 1}: 3,
+	}
+
+	// keys are sorted on the binary form of the owner then on the token id
+	p, err := MarshalMichelson(operators)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "050200000069"+
+		"070407070a000000160000538b84f868d22182ff1d046de7be9af92deb0bcd"+"0001"+"0003"+
+		"070407070a000000160000538b84f868d22182ff1d046de7be9af92deb0bcd"+"0002"+"0002"+
+		"070407070a000000160000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"+"0001"+"0001",
+		hex.EncodeToString(p.Pack()))
+
+	var d map[operatorKey]uint64
+	assert.Nil(t, UnmarshalMichelson(p, &d))
+	assert.EqualValues(t, operators, d)
+
+	_, err = MarshalMichelson(struct {
+		Trustees []string `michelson:"set(address)"`
+	}{[]string{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"}})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+}
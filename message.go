@@ -0,0 +1,206 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// SignPayloadType is the signing type of a wallet sign payload as defined by Beacon
+type SignPayloadType string
+
+const (
+	SignPayloadRaw       SignPayloadType = "raw"
+	SignPayloadOperation SignPayloadType = "operation"
+	SignPayloadMicheline SignPayloadType = "micheline"
+)
+
+const (
+	// MichelinePayloadTag is the first byte of a Micheline expression payload
+	MichelinePayloadTag = 0x05
+	// OperationPayloadTag is the first byte of an operation payload
+	OperationPayloadTag = 0x03
+	// michelineStringTag is the binary tag of a Micheline string
+	michelineStringTag = 0x01
+)
+
+// SignPayload is a decoded wallet sign payload. The dApp URL, timestamp and
+// message are only set for a Micheline string in the standard message format
+// "Tezos Signed Message: <dApp URL> <timestamp> <message>".
+type SignPayload struct {
+	Type      SignPayloadType
+	Bytes     []byte
+	Text      string
+	DAppURL   string
+	Timestamp time.Time
+	Message   string
+}
+
+// FormatSignPayload formats a message in the standard format used by Temple,
+// Kukai and Umami and encodes it as a Micheline string payload
+func FormatSignPayload(dAppURL string, timestamp time.Time, message string) []byte {
+	text := strings.Join([]string{
+		DefaultSignPrefix,
+		dAppURL,
+		timestamp.UTC().Format(time.RFC3339),
+		message,
+	}, " ")
+
+	buf := bytes.NewBuffer([]byte{MichelinePayloadTag, michelineStringTag})
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(text)))
+	buf.WriteString(text)
+	return buf.Bytes()
+}
+
+// ParseSignPayload decodes a wallet sign payload and detects its signing type
+func ParseSignPayload(payload []byte) (*SignPayload, error) {
+	if len(payload) == 0 {
+		return nil, ErrInvalidSignPayload
+	}
+
+	p := &SignPayload{
+		Type:  SignPayloadRaw,
+		Bytes: payload,
+	}
+	switch payload[0] {
+	case OperationPayloadTag:
+		p.Type = SignPayloadOperation
+	case MichelinePayloadTag:
+		var prim micheline.Prim
+		if err := prim.UnmarshalBinary(payload[1:]); err != nil {
+			return nil, ErrInvalidSignPayload
+		}
+		p.Type = SignPayloadMicheline
+		if prim.Type == micheline.PrimString {
+			p.Text = prim.String
+			p.parseText()
+		}
+	}
+	return p, nil
+}
+
+// parseText splits the text of the payload in the standard message format
+func (p *SignPayload) parseText() {
+	fields := strings.SplitN(strings.TrimPrefix(p.Text, DefaultSignPrefix+" "), " ", 3)
+	if !strings.HasPrefix(p.Text, DefaultSignPrefix+" ") || len(fields) < 3 {
+		p.Message = p.Text
+		return
+	}
+
+	ts, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		p.Message = p.Text
+		return
+	}
+	p.DAppURL = fields[0]
+	p.Timestamp = ts
+	p.Message = fields[2]
+}
+
+// VerifySignPayload verifies the signature of a wallet sign payload with a public key
+func VerifySignPayload(publicKey string, payload []byte, signature string) error {
+	key, err := tezos.ParseKey(publicKey)
+	if err != nil {
+		return ErrInvalidPublicKey
+	}
+	return verifyPayload(key, payload, signature)
+}
+
+// VerifyMessage verifies the signature of a message signed by a vault wallet with a public key
+func VerifyMessage(publicKey string, message []byte, signature string) error {
+	return VerifySignPayload(publicKey, messagePayload(message), signature)
+}
+
+// VerifyAuthTransferMessage verifies the signature of an authorized transfer message with a public key
+func VerifyAuthTransferMessage(publicKey, to, contractAddress, tokenID string, expiry time.Time, signature string) error {
+	m, err := authTransferMessage(to, contractAddress, tokenID, expiry)
+	if err != nil {
+		return err
+	}
+	return VerifyMessage(publicKey, m, signature)
+}
+
+// VerifySignPayload verifies the signature of a wallet sign payload. The signer
+// is either a public key or an address whose public key is revealed on chain.
+func (w *Wallet) VerifySignPayload(signer string, payload []byte, signature string) error {
+	key, err := w.signerKey(context.Background(), signer)
+	if err != nil {
+		return err
+	}
+	return verifyPayload(key, payload, signature)
+}
+
+// VerifyMessage verifies the signature of a message signed by a vault wallet.
+// The signer is either a public key or an address whose public key is revealed on chain.
+func (w *Wallet) VerifyMessage(signer string, message []byte, signature string) error {
+	return w.VerifySignPayload(signer, messagePayload(message), signature)
+}
+
+// VerifyAuthTransferMessage verifies the signature of an authorized transfer message.
+// The signer is either a public key or an address whose public key is revealed on chain.
+func (w *Wallet) VerifyAuthTransferMessage(signer, to, contractAddress, tokenID string, expiry time.Time, signature string) error {
+	m, err := authTransferMessage(to, contractAddress, tokenID, expiry)
+	if err != nil {
+		return err
+	}
+	return w.VerifyMessage(signer, m, signature)
+}
+
+// signerKey resolves the public key of a signer. The key of an address is
+// taken from the wallet signer or the manager key on chain.
+func (w *Wallet) signerKey(ctx context.Context, signer string) (tezos.Key, error) {
+	if tezos.IsPublicKey(signer) {
+		key, err := tezos.ParseKey(signer)
+		if err != nil {
+			return tezos.InvalidKey, ErrInvalidPublicKey
+		}
+		return key, nil
+	}
+
+	addr, err := tezos.ParseAddress(signer)
+	if err != nil || !addr.IsEOA() {
+		return tezos.InvalidKey, ErrInvalidAddress
+	}
+	if addr.Equal(w.address) {
+		return w.signer.GetKey(ctx, addr)
+	}
+	if w.IsOffline() {
+		return tezos.InvalidKey, ErrPublicKeyUnavailable
+	}
+
+	key, err := w.rpcClient.GetManagerKey(ctx, addr, rpc.Head)
+	if err != nil {
+		return tezos.InvalidKey, err
+	}
+	if !key.IsValid() {
+		return tezos.InvalidKey, ErrPublicKeyUnavailable
+	}
+	return key, nil
+}
+
+// verifyPayload verifies a signature over the blake2b digest of a payload
+func verifyPayload(key tezos.Key, payload []byte, signature string) error {
+	sig, err := tezos.ParseSignature(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !key.IsValid() {
+		return ErrInvalidPublicKey
+	}
+	// tzgo does not verify bls signatures
+	if key.Type == tezos.KeyTypeBls12_381 {
+		return ErrUnsupportedKeyType
+	}
+
+	d := tezos.Digest(payload)
+	if err := key.Verify(d[:], sig); err != nil {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
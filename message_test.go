@@ -0,0 +1,100 @@
+package tezos
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyMessage(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	for _, kt := range []tezos.KeyType{tezos.KeyTypeEd25519, tezos.KeyTypeSecp256k1, tezos.KeyTypeP256} {
+		w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU", WithKeyType(kt))
+		assert.Nil(t, err)
+		pk := w.privateKey.Public().String()
+
+		sig, err := w.signMessage([]byte("hello"))
+		assert.Nil(t, err)
+		assert.Nil(t, VerifyMessage(pk, []byte("hello"), sig))
+		assert.EqualError(t, VerifyMessage(pk, []byte("hallo"), sig), ErrSignatureMismatch.Error())
+		assert.Nil(t, w.VerifyMessage(w.Account(), []byte("hello"), sig))
+		assert.Nil(t, w.VerifyMessage(pk, []byte("hello"), sig))
+	}
+
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	sig, err := w.signMessage([]byte("hello"))
+	assert.Nil(t, err)
+
+	assert.EqualError(t, VerifyMessage(w.Account(), []byte("hello"), sig), ErrInvalidPublicKey.Error())
+	assert.EqualError(t, VerifyMessage(w.privateKey.Public().String(), []byte("hello"), "sig"), ErrInvalidSignature.Error())
+	assert.EqualError(t, w.VerifyMessage("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", []byte("hello"), sig), ErrPublicKeyUnavailable.Error())
+	assert.EqualError(t, w.VerifyMessage("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", []byte("hello"), sig), ErrInvalidAddress.Error())
+}
+
+func TestVerifyAuthTransferMessage(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	pk := w.privateKey.Public().String()
+
+	expiry := time.Unix(1700000000, 0)
+	sig, err := w.SignAuthTransferMessage("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "1", expiry)
+	assert.Nil(t, err)
+
+	assert.Nil(t, VerifyAuthTransferMessage(pk, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "1", expiry, sig))
+	assert.Nil(t, w.VerifyAuthTransferMessage(w.Account(), "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "1", expiry, sig))
+	assert.EqualError(t,
+		VerifyAuthTransferMessage(pk, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "2", expiry, sig),
+		ErrSignatureMismatch.Error())
+	assert.EqualError(t,
+		VerifyAuthTransferMessage(pk, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "token", expiry, sig),
+		ErrInvalidTokenID.Error())
+}
+
+func TestSignPayload(t *testing.T) {
+	ts := time.Date(2021, 1, 14, 15, 16, 4, 0, time.UTC)
+	payload := FormatSignPayload("mydapp.com", ts, "Hello world!")
+	text := "Tezos Signed Message: mydapp.com 2021-01-14T15:16:04Z Hello world!"
+	assert.EqualValues(t, "0501000000"+hex.EncodeToString([]byte{byte(len(text))})+hex.EncodeToString([]byte(text)), hex.EncodeToString(payload))
+
+	p, err := ParseSignPayload(payload)
+	assert.Nil(t, err)
+	assert.EqualValues(t, SignPayloadMicheline, p.Type)
+	assert.EqualValues(t, text, p.Text)
+	assert.EqualValues(t, "mydapp.com", p.DAppURL)
+	assert.True(t, ts.Equal(p.Timestamp))
+	assert.EqualValues(t, "Hello world!", p.Message)
+
+	// a micheline string in a custom format
+	p, err = ParseSignPayload(append([]byte{0x05, 0x01, 0, 0, 0, 5}, "hello"...))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "hello", p.Message)
+	assert.EqualValues(t, "", p.DAppURL)
+
+	p, err = ParseSignPayload([]byte{0x03, 0x01})
+	assert.Nil(t, err)
+	assert.EqualValues(t, SignPayloadOperation, p.Type)
+
+	p, err = ParseSignPayload([]byte("raw message"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, SignPayloadRaw, p.Type)
+
+	_, err = ParseSignPayload([]byte{0x05, 0xff})
+	assert.EqualError(t, err, ErrInvalidSignPayload.Error())
+	_, err = ParseSignPayload(nil)
+	assert.EqualError(t, err, ErrInvalidSignPayload.Error())
+
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	sig, err := w.Signer().SignBytes(context.Background(), w.Address(), payload)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifySignPayload(w.privateKey.Public().String(), payload, sig.String()))
+	assert.Nil(t, w.VerifySignPayload(w.Account(), payload, sig.Generic()))
+	assert.EqualError(t, VerifySignPayload(w.privateKey.Public().String(), payload[1:], sig.String()), ErrSignatureMismatch.Error())
+}
@@ -0,0 +1,905 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// maxSafeFloatInt is the largest integer a float64 holds exactly
+const maxSafeFloatInt = 1 << 53
+
+// EncodeValue converts a Go or JSON value into the Michelson value of a type in
+// the optimized form which is produced by PACK. Pairs are given as objects keyed
+// by the field annotations or as arrays of the right comb, ors as objects with a
+// single Left or Right key, options as null or the value, and maps as objects or
+// as arrays of [key, value] arrays. Null lists, sets and maps are empty.
+// Go structs are objects keyed by their JSON field names. Elements of sets and
+// keys of maps are sorted in the order of COMPARE, and duplicates are rejected.
+func EncodeValue(typ micheline.Type, v interface{}) (micheline.Prim, error) {
+	v, err := normalizeValue(v)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	return encodeValue(typ.Prim, v)
+}
+
+// PackValue encodes a Go or JSON value of a Michelson type exactly as PACK does on-chain
+func PackValue(typ micheline.Type, v interface{}) ([]byte, error) {
+	p, err := EncodeValue(typ, v)
+	if err != nil {
+		return nil, err
+	}
+	return p.Pack(), nil
+}
+
+// SignTypedMessage packs a Go or JSON value of a Michelson type and signs it with
+// the wallet signer. A contract verifies it with CHECK_SIGNATURE on the PACK of the value.
+func (w *Wallet) SignTypedMessage(typ micheline.Type, v interface{}) (string, error) {
+	b, err := PackValue(typ, v)
+	if err != nil {
+		return "", err
+	}
+	sig, err := w.signer.SignBytes(context.Background(), w.address, b)
+	if err != nil {
+		return "", ErrSignFailed
+	}
+	return sig.Generic(), nil
+}
+
+// VerifyTypedMessage verifies the signature of a packed Michelson value with a public key
+func VerifyTypedMessage(publicKey string, typ micheline.Type, v interface{}, signature string) error {
+	b, err := PackValue(typ, v)
+	if err != nil {
+		return err
+	}
+	return VerifySignPayload(publicKey, b, signature)
+}
+
+// normalizeValue converts a Go value which is not handled by the encoder into
+// its generic form. Structs become objects keyed by their JSON field names,
+// slices arrays and maps with string keys objects, so their field values are
+// encoded as they are. Other values are converted through their JSON encoding.
+func normalizeValue(v interface{}) (interface{}, error) {
+	switch v.(type) {
+	case nil, bool, string, json.Number, float64, int, int64, int32, uint, uint64, uint32,
+		*big.Int, tezos.Z, tezos.N, []byte, tezos.HexBytes, tezos.Address, tezos.Key,
+		tezos.Signature, tezos.ChainIdHash, time.Time, micheline.Prim,
+		[]interface{}, map[string]interface{}:
+		return v, nil
+	}
+	if _, ok := v.(json.Marshaler); !ok {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Pointer:
+			if rv.IsNil() {
+				return nil, nil
+			}
+			return normalizeValue(rv.Elem().Interface())
+		case reflect.Struct:
+			m := map[string]interface{}{}
+			structValues(rv, m)
+			return m, nil
+		case reflect.Slice, reflect.Array:
+			if rv.Type().Elem().Kind() == reflect.Uint8 {
+				b := make([]byte, rv.Len())
+				reflect.Copy(reflect.ValueOf(b), rv)
+				return b, nil
+			}
+			if rv.Kind() == reflect.Slice && rv.IsNil() {
+				return nil, nil
+			}
+			a := make([]interface{}, rv.Len())
+			for i := range a {
+				a[i] = rv.Index(i).Interface()
+			}
+			return a, nil
+		case reflect.Map:
+			if rv.Type().Key().Kind() == reflect.String {
+				if rv.IsNil() {
+					return nil, nil
+				}
+				m := make(map[string]interface{}, rv.Len())
+				iter := rv.MapRange()
+				for iter.Next() {
+					m[iter.Key().String()] = iter.Value().Interface()
+				}
+				return m, nil
+			}
+		}
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var n interface{}
+	if err := d.Decode(&n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// structValues adds the exported fields of a struct to an object keyed by their
+// JSON names. Fields of embedded structs without a name are added like JSON does.
+func structValues(rv reflect.Value, m map[string]interface{}) {
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			structValues(rv.Field(i), m)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		m[name] = rv.Field(i).Interface()
+	}
+}
+
+func encodeValue(typ micheline.Prim, v interface{}) (micheline.Prim, error) {
+	v, err := normalizeValue(v)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	// an already encoded value is used as is
+	if p, ok := v.(micheline.Prim); ok {
+		return p, nil
+	}
+
+	switch typ.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ:
+		i, err := valueInt(v)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		if typ.OpCode != micheline.T_INT && i.Sign() < 0 {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewBig(i), nil
+
+	case micheline.T_TIMESTAMP:
+		if t, ok := v.(time.Time); ok {
+			return micheline.NewInt64(t.Unix()), nil
+		}
+		if s, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return micheline.NewInt64(t.Unix()), nil
+			}
+		}
+		i, err := valueInt(v)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return micheline.NewBig(i), nil
+
+	case micheline.T_STRING:
+		s, ok := v.(string)
+		if !ok {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewString(s), nil
+
+	case micheline.T_BYTES:
+		switch b := v.(type) {
+		case []byte:
+			return micheline.NewBytes(b), nil
+		case tezos.HexBytes:
+			return micheline.NewBytes(b), nil
+		case string:
+			buf, err := hex.DecodeString(strings.TrimPrefix(b, "0x"))
+			if err != nil {
+				return micheline.InvalidPrim, typeError(typ, v)
+			}
+			return micheline.NewBytes(buf), nil
+		}
+		return micheline.InvalidPrim, typeError(typ, v)
+
+	case micheline.T_BOOL:
+		b, ok := v.(bool)
+		if !ok {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		if b {
+			return micheline.NewPrim(micheline.D_TRUE), nil
+		}
+		return micheline.NewPrim(micheline.D_FALSE), nil
+
+	case micheline.T_UNIT:
+		return micheline.NewPrim(micheline.D_UNIT), nil
+
+	case micheline.T_ADDRESS, micheline.T_CONTRACT:
+		addr, entrypoint, err := valueAddress(v)
+		if err != nil {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewBytes(append(addr.EncodePadded(), entrypoint...)), nil
+
+	case micheline.T_KEY_HASH:
+		addr, entrypoint, err := valueAddress(v)
+		if err != nil || !addr.IsEOA() || entrypoint != "" {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewBytes(addr.Encode()), nil
+
+	case micheline.T_KEY:
+		k, ok := v.(tezos.Key)
+		if s, isString := v.(string); isString {
+			var err error
+			k, err = tezos.ParseKey(s)
+			ok = err == nil
+		}
+		if !ok || !k.IsValid() {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewBytes(k.Bytes()), nil
+
+	case micheline.T_SIGNATURE:
+		sig, ok := v.(tezos.Signature)
+		if s, isString := v.(string); isString {
+			var err error
+			sig, err = tezos.ParseSignature(s)
+			ok = err == nil
+		}
+		if !ok || !sig.IsValid() {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		// the signature is packed without its type tag
+		return micheline.NewBytes(sig.Data), nil
+
+	case micheline.T_CHAIN_ID:
+		cid, ok := v.(tezos.ChainIdHash)
+		if s, isString := v.(string); isString {
+			var err error
+			cid, err = tezos.ParseChainIdHash(s)
+			ok = err == nil
+		}
+		if !ok {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		return micheline.NewBytes(cid.Bytes()), nil
+
+	case micheline.T_OPTION:
+		if v == nil {
+			return micheline.NewPrim(micheline.D_NONE), nil
+		}
+		p, err := encodeValue(typ.Args[0], v)
+		if err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return micheline.NewCode(micheline.D_SOME, p), nil
+
+	case micheline.T_OR:
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		if l, ok := m["Left"]; ok {
+			p, err := encodeValue(typ.Args[0], l)
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			return micheline.NewCode(micheline.D_LEFT, p), nil
+		}
+		if r, ok := m["Right"]; ok {
+			p, err := encodeValue(typ.Args[1], r)
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			return micheline.NewCode(micheline.D_RIGHT, p), nil
+		}
+		return micheline.InvalidPrim, typeError(typ, v)
+
+	case micheline.T_PAIR:
+		return encodePair(typ, v)
+
+	case micheline.T_LIST, micheline.T_SET:
+		l, ok := v.([]interface{})
+		if !ok && v != nil {
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		seq := micheline.NewSeq()
+		for _, e := range l {
+			p, err := encodeValue(typ.Args[0], e)
+			if err != nil {
+				return micheline.InvalidPrim, err
+			}
+			seq.Args = append(seq.Args, p)
+		}
+		if typ.OpCode == micheline.T_SET {
+			if err := sortValues(typ.Args[0], seq.Args, func(p micheline.Prim) micheline.Prim { return p }); err != nil {
+				return micheline.InvalidPrim, err
+			}
+		}
+		return seq, nil
+
+	case micheline.T_MAP, micheline.T_BIG_MAP:
+		seq := micheline.NewSeq()
+		switch m := v.(type) {
+		case nil:
+		case map[string]interface{}:
+			for k, e := range m {
+				kv, err := mapKey(typ.Args[0], k)
+				if err != nil {
+					return micheline.InvalidPrim, err
+				}
+				elt, err := encodeMapElem(typ, kv, e)
+				if err != nil {
+					return micheline.InvalidPrim, err
+				}
+				seq.Args = append(seq.Args, elt)
+			}
+		case []interface{}:
+			// keys which are not strings are given as [key, value] arrays
+			for _, e := range m {
+				kv, ok := e.([]interface{})
+				if !ok || len(kv) != 2 {
+					return micheline.InvalidPrim, typeError(typ, v)
+				}
+				elt, err := encodeMapElem(typ, kv[0], kv[1])
+				if err != nil {
+					return micheline.InvalidPrim, err
+				}
+				seq.Args = append(seq.Args, elt)
+			}
+		default:
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+		if err := sortValues(typ.Args[0], seq.Args, func(p micheline.Prim) micheline.Prim { return p.Args[0] }); err != nil {
+			return micheline.InvalidPrim, err
+		}
+		return seq, nil
+	}
+
+	return micheline.InvalidPrim, fmt.Errorf("%w: %s", ErrUnsupportedMichelsonType, typ.OpCode)
+}
+
+// encodeMapElem converts a key and a value into an element of a map type
+func encodeMapElem(typ micheline.Prim, k, v interface{}) (micheline.Prim, error) {
+	kp, err := encodeValue(typ.Args[0], k)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	vp, err := encodeValue(typ.Args[1], v)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	return micheline.NewMapElem(kp, vp), nil
+}
+
+// encodePair converts an object keyed by field annotations or an array of the
+// right comb into a pair
+func encodePair(typ micheline.Prim, v interface{}) (micheline.Prim, error) {
+	// comb pair types with more than two args are handled as right combs
+	if len(typ.Args) > 2 {
+		typ = micheline.NewPairType(typ.Args[0], micheline.NewCombPairType(typ.Args[1:]...), typ.Anno...)
+	}
+	if len(typ.Args) != 2 {
+		return micheline.InvalidPrim, typeError(typ, v)
+	}
+
+	var l, r interface{}
+	switch val := v.(type) {
+	case []interface{}:
+		switch {
+		case len(val) == 2:
+			l, r = val[0], val[1]
+		case len(val) > 2 && typ.Args[1].OpCode == micheline.T_PAIR:
+			l, r = val[0], val[1:]
+		default:
+			return micheline.InvalidPrim, typeError(typ, v)
+		}
+	case map[string]interface{}:
+		var err error
+		if l, err = pairField(typ.Args[0], val); err != nil {
+			return micheline.InvalidPrim, err
+		}
+		if r, err = pairField(typ.Args[1], val); err != nil {
+			return micheline.InvalidPrim, err
+		}
+	default:
+		return micheline.InvalidPrim, typeError(typ, v)
+	}
+
+	lp, err := encodeValue(typ.Args[0], l)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	rp, err := encodeValue(typ.Args[1], r)
+	if err != nil {
+		return micheline.InvalidPrim, err
+	}
+	return micheline.NewPair(lp, rp), nil
+}
+
+// pairField returns the value of a pair member from an object. A nested pair
+// without annotation takes its fields from the same object.
+func pairField(typ micheline.Prim, m map[string]interface{}) (interface{}, error) {
+	label := typ.GetFieldAnnoAny()
+	if label == "" {
+		if typ.OpCode == micheline.T_PAIR {
+			return m, nil
+		}
+		return nil, fmt.Errorf("%w: %s has no field annotation", ErrInvalidMichelsonValue, typ.OpCode)
+	}
+	v, ok := m[label]
+	if !ok && typ.OpCode != micheline.T_OPTION {
+		return nil, fmt.Errorf("%w: missing field %s", ErrInvalidMichelsonValue, label)
+	}
+	return v, nil
+}
+
+// mapKey converts a JSON object key into a value of the key type
+func mapKey(typ micheline.Prim, k string) (interface{}, error) {
+	switch typ.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ, micheline.T_TIMESTAMP:
+		if _, err := strconv.ParseInt(k, 10, 64); err == nil {
+			return json.Number(k), nil
+		}
+	case micheline.T_BOOL:
+		switch k {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%w: %s is not a valid %s", ErrInvalidMichelsonValue, k, typ.OpCode)
+	}
+	return k, nil
+}
+
+// sortValues sorts the elements of a set or map in the order of COMPARE on
+// their comparable value of type typ. Equal values are rejected.
+func sortValues(typ micheline.Prim, elts []micheline.Prim, key func(micheline.Prim) micheline.Prim) error {
+	var err error
+	sort.SliceStable(elts, func(i, j int) bool {
+		c, cerr := compareValues(typ, key(elts[i]), key(elts[j]))
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c < 0
+	})
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(elts); i++ {
+		if c, _ := compareValues(typ, key(elts[i-1]), key(elts[i])); c == 0 {
+			return fmt.Errorf("%w: duplicate %s", ErrInvalidMichelsonValue, key(elts[i]).Dump())
+		}
+	}
+	return nil
+}
+
+// compareValues compares two values of a comparable type like COMPARE does.
+// Addresses, keys and the other hashes are compared on their binary form, so
+// values in their readable form are converted first. When typ is not known
+// the order follows the form of the values.
+func compareValues(typ micheline.Prim, a, b micheline.Prim) (int, error) {
+	switch typ.OpCode {
+	case micheline.T_ADDRESS, micheline.T_CONTRACT, micheline.T_KEY_HASH, micheline.T_KEY,
+		micheline.T_SIGNATURE, micheline.T_CHAIN_ID, micheline.T_TIMESTAMP:
+		var err error
+		if a.Type == micheline.PrimString {
+			if a, err = encodeValue(typ, a.String); err != nil {
+				return 0, err
+			}
+		}
+		if b.Type == micheline.PrimString {
+			if b, err = encodeValue(typ, b.String); err != nil {
+				return 0, err
+			}
+		}
+	case micheline.T_PAIR:
+		if len(typ.Args) > 2 {
+			typ = micheline.NewPairType(typ.Args[0], micheline.NewCombPairType(typ.Args[1:]...))
+		}
+	}
+
+	switch {
+	case a.Type == micheline.PrimInt && b.Type == micheline.PrimInt:
+		return a.Int.Cmp(b.Int), nil
+	case a.Type == micheline.PrimString && b.Type == micheline.PrimString:
+		return strings.Compare(a.String, b.String), nil
+	case a.Type == micheline.PrimBytes && b.Type == micheline.PrimBytes:
+		return bytes.Compare(a.Bytes, b.Bytes), nil
+	}
+
+	a, b = combPair(a), combPair(b)
+	switch {
+	case a.OpCode == micheline.D_UNIT && b.OpCode == micheline.D_UNIT:
+		return 0, nil
+	case isBool(a) && isBool(b):
+		return boolRank(a) - boolRank(b), nil
+	case isOption(a) && isOption(b):
+		if a.OpCode != b.OpCode || a.OpCode == micheline.D_NONE {
+			return optionRank(a) - optionRank(b), nil
+		}
+		return compareValues(typeArg(typ, 0), a.Args[0], b.Args[0])
+	case isOr(a) && isOr(b):
+		if a.OpCode != b.OpCode {
+			return orRank(a) - orRank(b), nil
+		}
+		if a.OpCode == micheline.D_LEFT {
+			return compareValues(typeArg(typ, 0), a.Args[0], b.Args[0])
+		}
+		return compareValues(typeArg(typ, 1), a.Args[0], b.Args[0])
+	case a.OpCode == micheline.D_PAIR && b.OpCode == micheline.D_PAIR && len(a.Args) == 2 && len(b.Args) == 2:
+		c, err := compareValues(typeArg(typ, 0), a.Args[0], b.Args[0])
+		if err != nil || c != 0 {
+			return c, err
+		}
+		return compareValues(typeArg(typ, 1), a.Args[1], b.Args[1])
+	}
+	return 0, fmt.Errorf("%w: %s and %s are not comparable", ErrInvalidMichelsonValue, a.Dump(), b.Dump())
+}
+
+// combPair turns a pair value with more than two args or written as a sequence
+// into a pair of its right comb
+func combPair(p micheline.Prim) micheline.Prim {
+	if (p.OpCode == micheline.D_PAIR || p.Type == micheline.PrimSequence) && len(p.Args) > 2 {
+		return micheline.NewPair(p.Args[0], combPair(micheline.NewSeq(p.Args[1:]...)))
+	}
+	if p.Type == micheline.PrimSequence && len(p.Args) == 2 {
+		return micheline.NewPair(p.Args[0], p.Args[1])
+	}
+	return p
+}
+
+func typeArg(typ micheline.Prim, i int) micheline.Prim {
+	if i >= len(typ.Args) {
+		return micheline.InvalidPrim
+	}
+	return typ.Args[i]
+}
+
+func isBool(p micheline.Prim) bool {
+	return p.OpCode == micheline.D_FALSE || p.OpCode == micheline.D_TRUE
+}
+
+func boolRank(p micheline.Prim) int {
+	if p.OpCode == micheline.D_TRUE {
+		return 1
+	}
+	return 0
+}
+
+func isOption(p micheline.Prim) bool {
+	return p.OpCode == micheline.D_NONE || (p.OpCode == micheline.D_SOME && len(p.Args) == 1)
+}
+
+func optionRank(p micheline.Prim) int {
+	if p.OpCode == micheline.D_SOME {
+		return 1
+	}
+	return 0
+}
+
+func isOr(p micheline.Prim) bool {
+	return (p.OpCode == micheline.D_LEFT || p.OpCode == micheline.D_RIGHT) && len(p.Args) == 1
+}
+
+func orRank(p micheline.Prim) int {
+	if p.OpCode == micheline.D_RIGHT {
+		return 1
+	}
+	return 0
+}
+
+// valueInt converts a number or a decimal string into an integer
+func valueInt(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case int:
+		return big.NewInt(int64(n)), nil
+	case int32:
+		return big.NewInt(int64(n)), nil
+	case int64:
+		return big.NewInt(n), nil
+	case uint:
+		return new(big.Int).SetUint64(uint64(n)), nil
+	case uint32:
+		return new(big.Int).SetUint64(uint64(n)), nil
+	case uint64:
+		return new(big.Int).SetUint64(n), nil
+	case float64:
+		// larger numbers may have been rounded when they were decoded
+		if n == float64(int64(n)) && math.Abs(n) <= maxSafeFloatInt {
+			return big.NewInt(int64(n)), nil
+		}
+	case *big.Int:
+		if n != nil {
+			return n, nil
+		}
+	case tezos.Z:
+		return n.Big(), nil
+	case tezos.N:
+		return big.NewInt(n.Int64()), nil
+	case json.Number:
+		if i, ok := new(big.Int).SetString(n.String(), 10); ok {
+			return i, nil
+		}
+	case string:
+		if i, ok := new(big.Int).SetString(n, 10); ok {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %v is not an integer", ErrInvalidMichelsonValue, v)
+}
+
+// valueAddress converts an address with an optional entrypoint like KT1...%mint
+func valueAddress(v interface{}) (tezos.Address, string, error) {
+	switch a := v.(type) {
+	case tezos.Address:
+		return a, "", nil
+	case string:
+		s, entrypoint, _ := strings.Cut(a, "%")
+		addr, err := tezos.ParseAddress(s)
+		if err != nil {
+			return tezos.InvalidAddress, "", err
+		}
+		if entrypoint == "default" {
+			entrypoint = ""
+		}
+		return addr, entrypoint, nil
+	}
+	return tezos.InvalidAddress, "", ErrInvalidAddress
+}
+
+func typeError(typ micheline.Prim, v interface{}) error {
+	return fmt.Errorf("%w: %v is not a valid %s", ErrInvalidMichelsonValue, v, typ.OpCode)
+}
+
+// DecodeValue converts a Michelson value of a type into a Go value. It is the
+// inverse of EncodeValue: integers and mutez become decimal strings, timestamps
+// RFC3339 strings, bytes hex strings, addresses, keys and signatures base58
+// strings, pairs objects keyed by the field annotations or arrays when a member
+// has no annotation, ors objects with a single Left or Right key, options null
+// or the value, maps objects, and big maps their id when only the id is known.
+// The result is stored in v through its JSON encoding.
+func DecodeValue(typ micheline.Type, p micheline.Prim, v interface{}) error {
+	d, err := decodeValue(typ.Prim, p)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeValue(typ micheline.Prim, p micheline.Prim) (interface{}, error) {
+	switch typ.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ:
+		if p.Type != micheline.PrimInt {
+			return nil, valueError(typ, p)
+		}
+		return p.Int.String(), nil
+
+	case micheline.T_TIMESTAMP:
+		switch p.Type {
+		case micheline.PrimInt:
+			if !p.Int.IsInt64() {
+				return nil, valueError(typ, p)
+			}
+			return time.Unix(p.Int.Int64(), 0).UTC().Format(time.RFC3339), nil
+		case micheline.PrimString:
+			return p.String, nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_STRING:
+		if p.Type != micheline.PrimString {
+			return nil, valueError(typ, p)
+		}
+		return p.String, nil
+
+	case micheline.T_BYTES:
+		if p.Type != micheline.PrimBytes {
+			return nil, valueError(typ, p)
+		}
+		return hex.EncodeToString(p.Bytes), nil
+
+	case micheline.T_BOOL:
+		switch p.OpCode {
+		case micheline.D_TRUE:
+			return true, nil
+		case micheline.D_FALSE:
+			return false, nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_UNIT:
+		return nil, nil
+
+	case micheline.T_ADDRESS, micheline.T_CONTRACT, micheline.T_KEY_HASH:
+		switch p.Type {
+		case micheline.PrimString:
+			return p.String, nil
+		case micheline.PrimBytes:
+			var addr tezos.Address
+			if err := addr.Decode(p.Bytes); err != nil {
+				return nil, valueError(typ, p)
+			}
+			s := addr.String()
+			if typ.OpCode != micheline.T_KEY_HASH && len(p.Bytes) > 22 {
+				s += "%" + string(p.Bytes[22:])
+			}
+			return s, nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_KEY:
+		switch p.Type {
+		case micheline.PrimString:
+			return p.String, nil
+		case micheline.PrimBytes:
+			var k tezos.Key
+			if err := k.UnmarshalBinary(p.Bytes); err != nil {
+				return nil, valueError(typ, p)
+			}
+			return k.String(), nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_SIGNATURE:
+		switch p.Type {
+		case micheline.PrimString:
+			return p.String, nil
+		case micheline.PrimBytes:
+			// the key type is not known from the raw signature
+			return tezos.NewSignature(tezos.SignatureTypeGeneric, p.Bytes).Generic(), nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_CHAIN_ID:
+		switch p.Type {
+		case micheline.PrimString:
+			return p.String, nil
+		case micheline.PrimBytes:
+			return tezos.NewChainIdHash(p.Bytes).String(), nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_OPTION:
+		switch p.OpCode {
+		case micheline.D_NONE:
+			return nil, nil
+		case micheline.D_SOME:
+			if len(p.Args) != 1 {
+				return nil, valueError(typ, p)
+			}
+			return decodeValue(typ.Args[0], p.Args[0])
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_OR:
+		if len(p.Args) != 1 {
+			return nil, valueError(typ, p)
+		}
+		switch p.OpCode {
+		case micheline.D_LEFT:
+			l, err := decodeValue(typ.Args[0], p.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"Left": l}, nil
+		case micheline.D_RIGHT:
+			r, err := decodeValue(typ.Args[1], p.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"Right": r}, nil
+		}
+		return nil, valueError(typ, p)
+
+	case micheline.T_PAIR:
+		return decodePair(typ, p)
+
+	case micheline.T_LIST, micheline.T_SET:
+		if p.Type != micheline.PrimSequence {
+			return nil, valueError(typ, p)
+		}
+		l := make([]interface{}, 0, len(p.Args))
+		for _, e := range p.Args {
+			d, err := decodeValue(typ.Args[0], e)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, d)
+		}
+		return l, nil
+
+	case micheline.T_MAP, micheline.T_BIG_MAP:
+		if typ.OpCode == micheline.T_BIG_MAP && p.Type == micheline.PrimInt {
+			return json.Number(p.Int.String()), nil
+		}
+		if p.Type != micheline.PrimSequence {
+			return nil, valueError(typ, p)
+		}
+		m := make(map[string]interface{}, len(p.Args))
+		for _, e := range p.Args {
+			if e.OpCode != micheline.D_ELT || len(e.Args) != 2 {
+				return nil, valueError(typ, p)
+			}
+			k, err := decodeValue(typ.Args[0], e.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			d, err := decodeValue(typ.Args[1], e.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = d
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedMichelsonType, typ.OpCode)
+}
+
+// decodePair converts a pair into an object keyed by field annotations, or
+// into an array of the right comb when a member has no annotation
+func decodePair(typ micheline.Prim, p micheline.Prim) (interface{}, error) {
+	// comb pair types and values with more than two args are handled as right combs
+	if len(typ.Args) > 2 {
+		typ = micheline.NewPairType(typ.Args[0], micheline.NewCombPairType(typ.Args[1:]...), typ.Anno...)
+	}
+	if (p.OpCode == micheline.D_PAIR || p.Type == micheline.PrimSequence) && len(p.Args) > 2 {
+		p = micheline.NewPair(p.Args[0], micheline.NewCombPair(p.Args[1:]...))
+	}
+	if len(typ.Args) != 2 || len(p.Args) != 2 || (p.OpCode != micheline.D_PAIR && p.Type != micheline.PrimSequence) {
+		return nil, valueError(typ, p)
+	}
+
+	l, err := decodeValue(typ.Args[0], p.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	r, err := decodeValue(typ.Args[1], p.Args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	for i, v := range []interface{}{l, r} {
+		arg := typ.Args[i]
+		if label := arg.GetFieldAnnoAny(); label != "" {
+			m[label] = v
+			continue
+		}
+		// members of a nested pair without annotation are part of the same object
+		if nested, ok := v.(map[string]interface{}); ok && arg.OpCode == micheline.T_PAIR {
+			for k, e := range nested {
+				m[k] = e
+			}
+			continue
+		}
+		return []interface{}{l, r}, nil
+	}
+	return m, nil
+}
+
+func valueError(typ micheline.Prim, p micheline.Prim) error {
+	return fmt.Errorf("%w: %s is not a valid %s", ErrInvalidMichelsonValue, p.Dump(), typ.OpCode)
+}
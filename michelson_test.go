@@ -0,0 +1,202 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackValue(t *testing.T) {
+	pack := func(typ string, v interface{}) string {
+		b, err := PackValue(micheline.MustParseType(typ), v)
+		assert.Nil(t, err)
+		return hex.EncodeToString(b)
+	}
+
+	assert.EqualValues(t, "050001", pack(`{"prim":"int"}`, 1))
+	assert.EqualValues(t, "050041", pack(`{"prim":"int"}`, -1))
+	assert.EqualValues(t, "0501000000026161", pack(`{"prim":"string"}`, "aa"))
+	assert.EqualValues(t, "050a00000002cafe", pack(`{"prim":"bytes"}`, "cafe"))
+	assert.EqualValues(t, "05030a", pack(`{"prim":"bool"}`, true))
+	assert.EqualValues(t, "05030b", pack(`{"prim":"unit"}`, nil))
+	assert.EqualValues(t, "050306", pack(`{"prim":"option","args":[{"prim":"nat"}]}`, nil))
+	assert.EqualValues(t, "0505090007", pack(`{"prim":"option","args":[{"prim":"nat"}]}`, 7))
+	assert.EqualValues(t, "0505050001", pack(`{"prim":"or","args":[{"prim":"nat"},{"prim":"string"}]}`, map[string]interface{}{"Left": 1}))
+	assert.EqualValues(t, "05070700010100000001"+"61", pack(`{"prim":"pair","args":[{"prim":"nat"},{"prim":"string"}]}`, []interface{}{1, "a"}))
+	assert.EqualValues(t, "050200000006000100020003", pack(`{"prim":"set","args":[{"prim":"nat"}]}`, []interface{}{3, 1, 2}))
+	assert.EqualValues(t, "050200000014"+"07040100000001610001"+"07040100000001620002",
+		pack(`{"prim":"map","args":[{"prim":"string"},{"prim":"nat"}]}`, map[string]interface{}{"b": 2, "a": 1}))
+
+	addr, _ := tezos.ParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	assert.EqualValues(t, hex.EncodeToString(micheline.NewAddress(addr).Pack()), pack(`{"prim":"address"}`, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"))
+	assert.EqualValues(t, "050a00000015", pack(`{"prim":"key_hash"}`, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")[:12])
+	// address with an entrypoint
+	assert.EqualValues(t, "050a0000001a", pack(`{"prim":"address"}`, "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX%mint")[:12])
+
+	_, err := PackValue(micheline.MustParseType(`{"prim":"nat"}`), -1)
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+	_, err = PackValue(micheline.MustParseType(`{"prim":"lambda","args":[{"prim":"unit"},{"prim":"unit"}]}`), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedMichelsonType)
+
+	// floats beyond the exact integer range of float64 are rejected
+	assert.EqualValues(t, pack(`{"prim":"nat"}`, "9007199254740992"), pack(`{"prim":"nat"}`, float64(1<<53)))
+	_, err = PackValue(micheline.MustParseType(`{"prim":"nat"}`), float64(1<<53+2))
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+
+	// bytes in structs are used as they are, not through base64
+	type record struct {
+		Data  []byte `json:"data"`
+		Count uint64 `json:"count"`
+		Skip  string `json:"-"`
+	}
+	typ := `{"prim":"pair","args":[{"prim":"bytes","annots":["%data"]},{"prim":"nat","annots":["%count"]}]}`
+	assert.EqualValues(t, pack(typ, []interface{}{"cafe", 1}), pack(typ, record{Data: []byte{0xca, 0xfe}, Count: 1}))
+	assert.EqualValues(t, pack(typ, []interface{}{"", "18446744073709551615"}), pack(typ, &record{Count: 18446744073709551615}))
+}
+
+func TestPackValueOrder(t *testing.T) {
+	pack := func(typ string, v interface{}) string {
+		b, err := PackValue(micheline.MustParseType(typ), v)
+		assert.Nil(t, err)
+		return hex.EncodeToString(b)
+	}
+	const (
+		tz1T = "0000538b84f868d22182ff1d046de7be9af92deb0bcd"
+		tz1b = "0000a91e9228c3743f7c55c500d6b33a0c8ab7f8fae9"
+		kt1E = "0140354b4d13786cafaf9e3692520e4f19af0a7db500"
+	)
+
+	// pairs are compared on their left then right members
+	assert.EqualValues(t, "05020000001e"+"07070001010000000161"+"07070001010000000162"+"07070002010000000161",
+		pack(`{"prim":"set","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"string"}]}]}`,
+			[]interface{}{[]interface{}{2, "a"}, []interface{}{1, "b"}, []interface{}{1, "a"}}))
+	// addresses are compared on their binary form, implicit before originated
+	assert.EqualValues(t, "050200000051"+"0a00000016"+tz1T+"0a00000016"+tz1b+"0a00000016"+kt1E,
+		pack(`{"prim":"set","args":[{"prim":"address"}]}`, []interface{}{
+			"KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd"}))
+	assert.EqualValues(t, "05020000005d"+"07040a00000016"+tz1T+"0003"+"07040a00000016"+tz1b+"0002"+"07040a00000016"+kt1E+"0001",
+		pack(`{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}]}`, map[string]interface{}{
+			"KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX": 1, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa": 2, "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd": 3}))
+	// keys which are not strings are given as [key, value] arrays
+	assert.EqualValues(t, "050200000069"+
+		"070407070a00000016"+tz1T+"0001"+"0003"+
+		"070407070a00000016"+tz1T+"0002"+"0002"+
+		"070407070a00000016"+tz1b+"0001"+"0001",
+		pack(`{"prim":"map","args":[{"prim":"pair","args":[{"prim":"address"},{"prim":"nat"}]},{"prim":"nat"}]}`, []interface{}{
+			[]interface{}{[]interface{}{"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", 1}, 1},
+			[]interface{}{[]interface{}{"tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", 2}, 2},
+			[]interface{}{[]interface{}{"tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", 1}, 3}}))
+	// Left before Right, None before Some and False before True
+	assert.EqualValues(t, "050200000014"+"05050005"+"05080306"+"050805090303"+"05080509030a",
+		pack(`{"prim":"set","args":[{"prim":"or","args":[{"prim":"nat"},{"prim":"option","args":[{"prim":"bool"}]}]}]}`, []interface{}{
+			map[string]interface{}{"Right": true}, map[string]interface{}{"Right": nil},
+			map[string]interface{}{"Left": 5}, map[string]interface{}{"Right": false}}))
+
+	// null lists and maps are empty
+	assert.EqualValues(t, "050200000000", pack(`{"prim":"list","args":[{"prim":"nat"}]}`, nil))
+	assert.EqualValues(t, "050200000000", pack(`{"prim":"map","args":[{"prim":"nat"},{"prim":"nat"}]}`, nil))
+
+	// duplicate elements and keys
+	_, err := PackValue(micheline.MustParseType(`{"prim":"set","args":[{"prim":"nat"}]}`), []interface{}{1, "1"})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+	_, err = PackValue(micheline.MustParseType(`{"prim":"map","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"bool"}]},{"prim":"nat"}]}`),
+		[]interface{}{[]interface{}{[]interface{}{1, true}, 1}, []interface{}{[]interface{}{1, true}, 2}})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+	_, err = PackValue(micheline.MustParseType(`{"prim":"map","args":[{"prim":"bool"},{"prim":"nat"}]}`), map[string]interface{}{"yes": 1})
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+}
+
+func TestSignTypedMessage(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	m := AuthTransferMessage{
+		Format:   AuthTransferFormatV2,
+		ChainID:  "NetXdQprcVkpaWU",
+		Contract: "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX",
+		To:       "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa",
+		TokenID:  "1",
+		Amount:   1,
+		Nonce:    7,
+		Expiry:   time.Unix(1700000000, 0),
+	}
+	typ := micheline.MustParseType(`{"prim":"pair","args":[
+		{"prim":"chain_id","annots":["%chain_id"]},
+		{"prim":"address","annots":["%contract"]},
+		{"prim":"address","annots":["%to"]},
+		{"prim":"nat","annots":["%token_id"]},
+		{"prim":"nat","annots":["%amount"]},
+		{"prim":"nat","annots":["%nonce"]},
+		{"prim":"timestamp","annots":["%expiry"]}]}`)
+
+	// a JSON tagged struct packs the same as the V2 authorized transfer
+	b, err := PackValue(typ, m)
+	assert.Nil(t, err)
+	p, err := m.Payload()
+	assert.Nil(t, err)
+	assert.EqualValues(t, p, b)
+
+	v := []interface{}{m.ChainID, m.Contract, m.To, "1", 1, 7, "2023-11-14T22:13:20Z"}
+	sig, err := w.SignTypedMessage(typ, v)
+	assert.Nil(t, err)
+	assert.Nil(t, VerifyAuthTransfer(w.privateKey.Public().String(), m, sig))
+	assert.Nil(t, VerifyTypedMessage(w.privateKey.Public().String(), typ, m, sig))
+}
+
+func TestDecodeValue(t *testing.T) {
+	typ := micheline.MustParseType(`{"prim":"pair","args":[
+		{"prim":"address","annots":["%owner"]},
+		{"prim":"nat","annots":["%balance"]},
+		{"prim":"bytes","annots":["%data"]},
+		{"prim":"timestamp","annots":["%since"]},
+		{"prim":"option","args":[{"prim":"string"}],"annots":["%note"]},
+		{"prim":"or","args":[{"prim":"unit"},{"prim":"bool"}],"annots":["%state"]},
+		{"prim":"map","args":[{"prim":"string"},{"prim":"int"}],"annots":["%scores"]},
+		{"prim":"big_map","args":[{"prim":"nat"},{"prim":"address"}],"annots":["%ledger"]},
+		{"prim":"pair","args":[{"prim":"nat"},{"prim":"string"}],"annots":["%point"]}]}`)
+
+	v := map[string]interface{}{
+		"owner":   "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX%mint",
+		"balance": "100",
+		"data":    "cafe",
+		"since":   "2023-11-14T22:13:20Z",
+		"note":    nil,
+		"state":   map[string]interface{}{"Right": true},
+		"scores":  map[string]interface{}{"a": "-1", "b": "2"},
+		"ledger":  map[string]interface{}{"1": "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa"},
+		"point":   []interface{}{"1", "x"},
+	}
+	p, err := EncodeValue(typ, v)
+	assert.Nil(t, err)
+
+	var d map[string]interface{}
+	assert.Nil(t, DecodeValue(typ, p, &d))
+	assert.EqualValues(t, v, d)
+
+	// readable values and big map ids
+	var s struct {
+		Owner  string    `json:"owner"`
+		Since  time.Time `json:"since"`
+		Ledger int64     `json:"ledger"`
+	}
+	p.Args[0] = micheline.NewString("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	p.Args[1].Args[1].Args[1].Args[1].Args[1].Args[1].Args[1].Args[0] = micheline.NewInt64(42)
+	assert.Nil(t, DecodeValue(typ, p, &s))
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", s.Owner)
+	assert.EqualValues(t, int64(1700000000), s.Since.Unix())
+	assert.EqualValues(t, 42, s.Ledger)
+
+	err = DecodeValue(typ, micheline.NewInt64(1), &d)
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+
+	// timestamps beyond the int64 range
+	var ts string
+	err = DecodeValue(micheline.MustParseType(`{"prim":"timestamp"}`), micheline.NewBig(new(big.Int).Lsh(big.NewInt(1), 64)), &ts)
+	assert.ErrorIs(t, err, ErrInvalidMichelsonValue)
+}
@@ -0,0 +1,59 @@
+package tezos
+
+import (
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultMnemonicEntropySize is the entropy bit size of a 24 words mnemonic
+const DefaultMnemonicEntropySize = 256
+
+// GenerateMnemonic generates a new 24 words BIP39 mnemonic
+func GenerateMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(DefaultMnemonicEntropySize)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic checks the words and the checksum of a BIP39 mnemonic
+func ValidateMnemonic(mnemonic string) error {
+	_, err := bip39.EntropyFromMnemonic(normalizeMnemonic(mnemonic))
+	switch err {
+	case nil:
+		return nil
+	case bip39.ErrChecksumIncorrect:
+		return ErrInvalidMnemonicChecksum
+	default:
+		return ErrInvalidMnemonic
+	}
+}
+
+// MnemonicToSeed validates a BIP39 mnemonic and converts it with an optional
+// passphrase to a seed. Both are NFKD normalized as BIP39 requires.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(normalizeMnemonic(mnemonic), norm.NFKD.String(passphrase)), nil
+}
+
+// NewWalletFromMnemonic creates a tezos wallet from a BIP39 mnemonic and an optional passphrase
+func NewWalletFromMnemonic(mnemonic, passphrase string, network string, rpcURL string, opts ...WalletOption) (*Wallet, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(seed)
+
+	return NewWallet(seed, network, rpcURL, opts...)
+}
+
+// normalizeMnemonic NFKD normalizes the mnemonic, lowercases the words and
+// collapses the spaces between them
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic))), " ")
+}
@@ -0,0 +1,44 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateMnemonic(t *testing.T) {
+	m, err := GenerateMnemonic()
+	assert.Nil(t, err)
+	assert.Len(t, strings.Fields(m), 24)
+	assert.Nil(t, ValidateMnemonic(m))
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	assert.Nil(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"))
+	assert.Nil(t, ValidateMnemonic("  Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon  about "))
+	assert.EqualError(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"), ErrInvalidMnemonicChecksum.Error())
+	assert.EqualError(t, ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon tezos"), ErrInvalidMnemonic.Error())
+	assert.EqualError(t, ValidateMnemonic("abandon about"), ErrInvalidMnemonic.Error())
+}
+
+func TestMnemonicToSeed(t *testing.T) {
+	seed, err := MnemonicToSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "TREZOR")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04", hex.EncodeToString(seed))
+
+	// the mnemonic and the passphrase are NFKD normalized
+	expected := "d5746b7c1adc93186e414a729c09e900089f2f6282c1c83c0583c6eb2016315e01c5b9a34030e8dff7f07b38b337cf9f804e095a0ac24cf4b17533f6bdd6b89e"
+	for _, passphrase := range []string{"caf\u00e9 \u2460", "cafe\u0301 1"} {
+		seed, err = MnemonicToSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", passphrase)
+		assert.Nil(t, err)
+		assert.EqualValues(t, expected, hex.EncodeToString(seed))
+	}
+	seed, err = MnemonicToSeed("\uff41\uff42\uff41\uff4e\uff44\uff4f\uff4e abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon\u3000about", "caf\u00e9 \u2460")
+	assert.Nil(t, err)
+	assert.EqualValues(t, expected, hex.EncodeToString(seed))
+
+	_, err = MnemonicToSeed("abandon about", "")
+	assert.EqualError(t, err, ErrInvalidMnemonic.Error())
+}
@@ -0,0 +1,165 @@
+package tezos
+
+import (
+	"context"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// OfflineParams are the chain states which are required to build an operation
+// without contacting a node
+type OfflineParams struct {
+	// Branch is the hash of the block the operation is based on
+	Branch string
+	// Counter is the current counter of the source account
+	Counter int64
+	// Reveal prepends a reveal operation for an account which is not revealed yet
+	Reveal bool
+	// Limits are the fee, gas and storage limits of each operation. They are
+	// optional when all operations are xtz transfers to implicit accounts or
+	// delegations, which get the default limits and the minimum fee.
+	Limits []tezos.Limits
+}
+
+// NewOfflineWallet creates a tezos wallet from a given seed which signs for a
+// given chain without contacting a node
+func NewOfflineWallet(seed []byte, chainID string, opts ...WalletOption) (*Wallet, error) {
+	o, mk, key, err := newWalletKeys(seed, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewOfflineWalletWithSigner(NewKeySigner(key), chainID)
+	if err != nil {
+		return nil, err
+	}
+	w.setKeys(o, mk, key)
+
+	return w, nil
+}
+
+// NewOfflineWalletWithSigner creates a tezos wallet which signs through a given
+// signer backend for a given chain without contacting a node
+func NewOfflineWalletWithSigner(s Signer, chainID string) (*Wallet, error) {
+	cid, err := tezos.ParseChainIdHash(chainID)
+	if err != nil {
+		return nil, ErrInvalidChainID
+	}
+
+	addrs, err := s.ListAddresses(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, ErrNoSignerAddress
+	}
+
+	return &Wallet{
+		chainID:      cid,
+		keyType:      addrs[0].KeyType(),
+		accountIndex: DefaultAccountIndex,
+		address:      addrs[0],
+		signer:       s,
+	}, nil
+}
+
+// SignOperation signs a completed operation with the wallet signer
+func (w *Wallet) SignOperation(op *codec.Op) (tezos.Signature, error) {
+	sig, err := w.signer.SignOperation(context.Background(), w.address, op)
+	if err != nil {
+		return tezos.InvalidSignature, err
+	}
+	op.WithSignature(sig)
+	return sig, nil
+}
+
+// SignOperationsOffline builds a list of operations with the given chain states and
+// signs it with the wallet signer. The signed operation can be broadcast by any node.
+func (w *Wallet) SignOperationsOffline(ops []codec.Operation, p OfflineParams) (*codec.Op, error) {
+	branch, err := tezos.ParseBlockHash(p.Branch)
+	if err != nil {
+		return nil, ErrInvalidBranch
+	}
+	if len(p.Limits) > 0 && len(p.Limits) != len(ops) {
+		return nil, ErrInvalidLimits
+	}
+
+	op := codec.NewOp().
+		WithParams(w.params()).
+		WithBranch(branch).
+		WithChainId(w.chainID)
+	for _, o := range ops {
+		op.WithContents(o)
+	}
+
+	limits := p.Limits
+	if p.Reveal {
+		key, err := w.signer.GetKey(context.Background(), w.address)
+		if err != nil {
+			return nil, err
+		}
+		reveal := &codec.Reveal{
+			PublicKey: key,
+		}
+		reveal.WithLimits(rpc.DefaultRevealLimits)
+		op.WithContentsFront(reveal)
+		if len(limits) > 0 {
+			limits = append([]tezos.Limits{rpc.DefaultRevealLimits}, limits...)
+		}
+	}
+
+	// set source on all ops
+	op.WithSource(w.address)
+
+	// add counters
+	counter := p.Counter + 1
+	for _, o := range op.Contents {
+		// skip non-manager ops
+		if o.GetCounter() < 0 {
+			continue
+		}
+		o.WithCounter(counter)
+		counter++
+	}
+
+	if len(limits) > 0 {
+		op.WithLimits(limits, 0)
+	} else {
+		for _, o := range op.Contents {
+			if o.Limits().GasLimit > 0 {
+				continue
+			}
+			l, ok := w.defaultOfflineLimits(o)
+			if !ok {
+				return nil, ErrMissingLimits
+			}
+			o.WithLimits(l)
+		}
+		op.WithMinFee()
+	}
+
+	if _, err := w.SignOperation(op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// defaultOfflineLimits returns the default limits of an operation whose cost is
+// known without simulating it
+func (w *Wallet) defaultOfflineLimits(o codec.Operation) (tezos.Limits, bool) {
+	switch op := o.(type) {
+	case *codec.Transaction:
+		if op.Parameters != nil || !op.Destination.IsEOA() {
+			return tezos.Limits{}, false
+		}
+		// the storage limit covers the allocation of an empty destination
+		l := rpc.DefaultTransferLimitsEOA
+		l.StorageLimit = w.params().OriginationSize
+		return l, true
+	case *codec.Delegation:
+		return rpc.DefaultDelegationLimitsEOA, true
+	}
+	return tezos.Limits{}, false
+}
@@ -0,0 +1,79 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOfflineWallet(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	assert.True(t, w.IsOffline())
+	assert.Nil(t, w.RPCClient())
+	assert.EqualValues(t, "tz1TFmv27hNN1CV4XFP5TceGzsmDCrWTdWpd", w.Account())
+	assert.EqualValues(t, "NetXdQprcVkpaWU", w.ChainID())
+
+	dw, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", dw.Account())
+	assert.True(t, dw.IsOffline())
+
+	_, err = w.SignAuthTransferMessage("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", "1", time.Now())
+	assert.Nil(t, err)
+
+	_, err = w.TransferXTZ("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", 1)
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+
+	_, err = NewOfflineWallet(s, "NetX")
+	assert.EqualError(t, err, ErrInvalidChainID.Error())
+}
+
+func TestSignOperationsOffline(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXnHfVqm9iesp")
+	assert.Nil(t, err)
+
+	to := tezos.MustParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	ops := []codec.Operation{
+		&codec.Transaction{Amount: 1000, Destination: to},
+		&codec.Transaction{Amount: 2000, Destination: to},
+	}
+	op, err := w.SignOperationsOffline(ops, OfflineParams{
+		Branch:  "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+		Counter: 10,
+		Reveal:  true,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, op.Contents, 3)
+	assert.EqualValues(t, tezos.OpTypeReveal, op.Contents[0].Kind())
+	for i, o := range op.Contents {
+		assert.EqualValues(t, 11+i, o.GetCounter())
+		assert.True(t, o.Limits().Fee > 0)
+		assert.True(t, o.Limits().GasLimit > 0)
+	}
+	assert.EqualValues(t, 257, op.Contents[1].Limits().StorageLimit)
+	assert.Nil(t, w.PrivateKey().Public().Verify(op.Digest(), op.Signature))
+
+	_, err = w.SignOperationsOffline(ops, OfflineParams{
+		Branch: "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+		Limits: []tezos.Limits{{Fee: 1000}},
+	})
+	assert.EqualError(t, err, ErrInvalidLimits.Error())
+
+	// contract calls can not be estimated offline
+	_, err = w.SignOperationsOffline([]codec.Operation{
+		&codec.Transaction{Destination: tezos.MustParseAddress("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX")},
+	}, OfflineParams{
+		Branch: "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+	})
+	assert.EqualError(t, err, ErrMissingLimits.Error())
+
+	_, err = w.SignOperationsOffline(ops, OfflineParams{Branch: "B"})
+	assert.EqualError(t, err, ErrInvalidBranch.Error())
+}
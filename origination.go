@@ -0,0 +1,213 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// OriginationOption configures the optional settings of an origination
+type OriginationOption func(*originationOptions)
+
+type originationOptions struct {
+	delegate string
+	balance  int64
+	maxBurn  int64
+}
+
+// WithOriginationDelegate sets the baker the new contract delegates to
+func WithOriginationDelegate(baker string) OriginationOption {
+	return func(o *originationOptions) {
+		o.delegate = baker
+	}
+}
+
+// WithOriginationBalance sets the amount in mutez transferred to the new contract
+func WithOriginationBalance(amount int64) OriginationOption {
+	return func(o *originationOptions) {
+		o.balance = amount
+	}
+}
+
+// WithMaxStorageBurn sets the maximum storage burn in mutez accepted for the
+// origination. The simulated burn is checked against it before signing.
+func WithMaxStorageBurn(amount int64) OriginationOption {
+	return func(o *originationOptions) {
+		o.maxBurn = amount
+	}
+}
+
+// ParseScript parses the code and the initial storage of a contract. Each of
+// them is given as Micheline JSON, or as binary Micheline either raw or hex
+// encoded.
+func ParseScript(code, storage []byte) (*micheline.Script, error) {
+	script := &micheline.Script{}
+
+	b := michelineBytes(code)
+	// binary code is prefixed with its size in a script
+	if len(b) > 0 && b[0] == byte(micheline.PrimSequence) {
+		b = append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+	if err := decodeMicheline(b, &script.Code); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContractScript, err)
+	}
+	if !script.Code.Param.IsValid() || !script.Code.Storage.IsValid() || !script.Code.Code.IsValid() {
+		return nil, ErrInvalidContractScript
+	}
+
+	if err := decodeMicheline(michelineBytes(storage), &script.Storage); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContractScript, err)
+	}
+	if !script.Storage.IsValid() {
+		return nil, ErrInvalidContractScript
+	}
+	return script, nil
+}
+
+// Originate deploys a contract with code and initial storage in Micheline JSON
+// or binary. It waits until the origination is included in a block and returns
+// the address of the new contract and the operation hash.
+func (w *Wallet) Originate(code, storage []byte, opts ...OriginationOption) (string, string, error) {
+	script, err := ParseScript(code, storage)
+	if err != nil {
+		return "", "", err
+	}
+	return w.OriginateScript(*script, opts...)
+}
+
+// OriginateScript deploys a contract like Originate from a parsed script
+func (w *Wallet) OriginateScript(script micheline.Script, opts ...OriginationOption) (string, string, error) {
+	o := originationOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	op, callOpts, err := w.originationOp(script, o)
+	if err != nil {
+		return "", "", err
+	}
+	rec, err := w.sendAndWait(op, callOpts, func(sim *rpc.Receipt) error {
+		if burn := sim.TotalCosts().Burn; o.maxBurn > 0 && burn > o.maxBurn {
+			return fmt.Errorf("%w: %d > max %d", ErrStorageBurnExceeded, burn, o.maxBurn)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	addr, err := originatedContract(rec)
+	if err != nil {
+		return "", "", err
+	}
+	return addr.String(), rec.Op.Hash.String(), nil
+}
+
+// SimulateOrigination simulates an origination and returns its costs, which
+// include the fee and the storage burn
+func (w *Wallet) SimulateOrigination(code, storage []byte, opts ...OriginationOption) (*tezos.Costs, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
+	script, err := ParseScript(code, storage)
+	if err != nil {
+		return nil, err
+	}
+	o := originationOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	op, callOpts, err := w.originationOp(*script, o)
+	if err != nil {
+		return nil, err
+	}
+	sim, err := w.prepare(context.Background(), op, callOpts, w.signer, w.address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := sim.TotalCosts()
+	c.Fee = op.Limits().Fee
+	return &c, nil
+}
+
+// originationOp constructs an operation which originates a contract
+func (w *Wallet) originationOp(script micheline.Script, o originationOptions) (*codec.Op, *rpc.CallOptions, error) {
+	if o.balance < 0 {
+		return nil, nil, ErrInvalidAmount
+	}
+	baker := tezos.InvalidAddress
+	if o.delegate != "" {
+		var err error
+		baker, err = tezos.ParseAddress(o.delegate)
+		if err != nil || !baker.IsEOA() {
+			return nil, nil, ErrInvalidAddress
+		}
+	}
+
+	opts := &rpc.CallOptions{
+		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
+		MaxFee: 10_000_000,
+	}
+
+	op := codec.NewOp().WithTTL(opts.TTL)
+	op.WithOriginationExt(script, baker, o.balance)
+
+	op.WithParams(w.params())
+
+	return op, opts, nil
+}
+
+// originatedContract returns the address of the contract originated by the
+// operation of a receipt
+func originatedContract(rec *rpc.Receipt) (tezos.Address, error) {
+	if rec == nil || rec.Op == nil {
+		return tezos.InvalidAddress, ErrContractNotOriginated
+	}
+	for _, c := range rec.Op.Contents {
+		if c.Kind() != tezos.OpTypeOrigination {
+			continue
+		}
+		if res := c.Result(); len(res.OriginatedContracts) > 0 {
+			return res.OriginatedContracts[0], nil
+		}
+	}
+	return tezos.InvalidAddress, ErrContractNotOriginated
+}
+
+type michelineDecoder interface {
+	json.Unmarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// decodeMicheline decodes Micheline JSON or binary
+func decodeMicheline(data []byte, v michelineDecoder) error {
+	if t := bytes.TrimSpace(data); len(t) > 0 && (t[0] == '{' || t[0] == '[') {
+		return v.UnmarshalJSON(t)
+	}
+	return v.UnmarshalBinary(data)
+}
+
+// michelineBytes decodes hex encoded binary Micheline. Other input is returned
+// as it is, since raw binary Micheline never starts with a hex digit.
+func michelineBytes(data []byte) []byte {
+	t := bytes.TrimPrefix(bytes.TrimSpace(data), []byte("0x"))
+	if len(t) == 0 {
+		return data
+	}
+	if b, err := hex.DecodeString(string(t)); err == nil {
+		return b
+	}
+	return data
+}
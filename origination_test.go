@@ -0,0 +1,83 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testContractCode    = `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"nat"}]},{"prim":"code","args":[[{"prim":"CDR"},{"prim":"NIL","args":[{"prim":"operation"}]},{"prim":"PAIR"}]]}]`
+	testContractStorage = `{"int":"42"}`
+)
+
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript([]byte(testContractCode), []byte(testContractStorage))
+	assert.Nil(t, err)
+	assert.EqualValues(t, micheline.T_NAT, script.StorageType().OpCode)
+	assert.EqualValues(t, int64(42), script.Storage.Int.Int64())
+
+	// binary code without the size prefix and hex encoded binary storage
+	code, err := script.Code.MarshalBinary()
+	assert.Nil(t, err)
+	storage, err := script.Storage.MarshalBinary()
+	assert.Nil(t, err)
+	s, err := ParseScript(code[4:], []byte(hex.EncodeToString(storage)))
+	assert.Nil(t, err)
+	assert.EqualValues(t, script.Code.Code, s.Code.Code)
+	assert.EqualValues(t, script.Storage, s.Storage)
+
+	// binary code with the size prefix and raw binary storage
+	s, err = ParseScript(code, storage)
+	assert.Nil(t, err)
+	assert.EqualValues(t, script.Storage, s.Storage)
+
+	_, err = ParseScript([]byte(`[{"prim":"parameter","args":[{"prim":"unit"}]}]`), []byte(testContractStorage))
+	assert.ErrorIs(t, err, ErrInvalidContractScript)
+	_, err = ParseScript([]byte(testContractCode), nil)
+	assert.ErrorIs(t, err, ErrInvalidContractScript)
+}
+
+func TestOriginate(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	_, _, err = w.Originate([]byte(testContractCode), []byte(testContractStorage))
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+	_, err = w.SimulateOrigination([]byte(testContractCode), []byte(testContractStorage))
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+
+	script, err := ParseScript([]byte(testContractCode), []byte(testContractStorage))
+	assert.Nil(t, err)
+
+	o := originationOptions{}
+	WithOriginationDelegate("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")(&o)
+	WithOriginationBalance(1_000_000)(&o)
+	op, _, err := w.originationOp(*script, o)
+	assert.Nil(t, err)
+	assert.Len(t, op.Contents, 1)
+	orig := op.Contents[0].(*codec.Origination)
+	assert.EqualValues(t, *script, orig.Script)
+	assert.EqualValues(t, "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", orig.Delegate.String())
+	assert.EqualValues(t, 1_000_000, orig.Balance)
+
+	_, _, err = w.originationOp(*script, originationOptions{delegate: "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"})
+	assert.EqualError(t, err, ErrInvalidAddress.Error())
+	_, _, err = w.originationOp(*script, originationOptions{balance: -1})
+	assert.EqualError(t, err, ErrInvalidAmount.Error())
+
+	_, err = originatedContract(nil)
+	assert.EqualError(t, err, ErrContractNotOriginated.Error())
+
+	var rop rpc.Operation
+	assert.Nil(t, json.Unmarshal([]byte(`{"hash":"ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE","contents":[{"kind":"origination","source":"tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa","balance":"0","metadata":{"operation_result":{"status":"applied","originated_contracts":["KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX"]}}}]}`), &rop))
+	addr, err := originatedContract(&rpc.Receipt{Op: &rop})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", addr.String())
+}
@@ -0,0 +1,109 @@
+package tezos
+
+import (
+	"sync"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+)
+
+// CallResult represents a sent operation with the costs of its simulation.
+// Fee, gas and storage limits are the values the operation is signed with.
+type CallResult struct {
+	Hash           string          `json:"hash"`
+	Size           int             `json:"size"`
+	Fee            int64           `json:"fee"`
+	GasLimit       int64           `json:"gas_limit"`
+	StorageLimit   int64           `json:"storage_limit"`
+	GasUsed        int64           `json:"gas_used"`
+	StorageUsed    int64           `json:"storage_used"`
+	Burn           int64           `json:"burn"`
+	BalanceUpdates []BalanceUpdate `json:"balance_updates"`
+}
+
+// BalanceUpdate represents a simulated balance change in mutez
+type BalanceUpdate struct {
+	Kind     string `json:"kind"`
+	Category string `json:"category,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Change   int64  `json:"change"`
+}
+
+// newCallResult returns the result of a signed operation from its simulation receipt
+func newCallResult(hash string, op *codec.Op, sim *rpc.Receipt) *CallResult {
+	limits := op.Limits()
+	costs := sim.TotalCosts()
+	res := &CallResult{
+		Hash:           hash,
+		Size:           len(op.Bytes()),
+		Fee:            limits.Fee,
+		GasLimit:       limits.GasLimit,
+		StorageLimit:   limits.StorageLimit,
+		GasUsed:        costs.GasUsed,
+		StorageUsed:    costs.StorageUsed,
+		Burn:           costs.Burn,
+		BalanceUpdates: []BalanceUpdate{},
+	}
+
+	// the fee updates of the simulation are left out as the fee is set afterwards
+	for _, c := range sim.Op.Contents {
+		res.addBalanceUpdates(c.Result().BalanceUpdates)
+		for _, in := range c.Meta().InternalResults {
+			res.addBalanceUpdates(in.Result.BalanceUpdates)
+		}
+	}
+	return res
+}
+
+func (r *CallResult) addBalanceUpdates(updates rpc.BalanceUpdates) {
+	for _, u := range updates {
+		b := BalanceUpdate{
+			Kind:     u.Kind,
+			Category: u.Category,
+			Change:   u.Change,
+		}
+		switch {
+		case u.Contract.IsValid():
+			b.Address = u.Contract.String()
+		case u.Delegate.IsValid():
+			b.Address = u.Delegate.String()
+		}
+		r.BalanceUpdates = append(r.BalanceUpdates, b)
+	}
+}
+
+// resultRecorder collects the results of the operations sent by a wallet. It
+// is safe for concurrent use.
+type resultRecorder struct {
+	mu      sync.Mutex
+	results []CallResult
+}
+
+// recording returns a copy of the wallet which records the results of the sent
+// operations. The copy shares the master key without owning it.
+func (w *Wallet) recording() (*Wallet, *resultRecorder) {
+	rw := *w
+	rw.ownsMaster = false
+	rw.recorder = &resultRecorder{}
+	return &rw, rw.recorder
+}
+
+// record adds the result of a sent operation
+func (r *resultRecorder) record(res CallResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+}
+
+// result returns a copy of the recorded result of an operation
+func (r *resultRecorder) result(hash string) (*CallResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.results) - 1; i >= 0; i-- {
+		if r.results[i].Hash == hash {
+			res := r.results[i]
+			return &res, true
+		}
+	}
+	return nil, false
+}
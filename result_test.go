@@ -0,0 +1,222 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/contract"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedContract struct {
+	hash string
+}
+
+func (c recordedContract) Deploy(wallet *Wallet, arguments json.RawMessage) (string, string, error) {
+	return "", "", nil
+}
+
+func (c recordedContract) Call(wallet *Wallet, method string, arguments json.RawMessage) (*string, error) {
+	if wallet.recorder != nil {
+		wallet.recorder.record(CallResult{Hash: c.hash, Fee: 1000})
+	}
+	return &c.hash, nil
+}
+
+// sendContract sends a transfer for each call through the wallet
+type sendContract struct {
+	to tezos.Address
+}
+
+func (c sendContract) Deploy(wallet *Wallet, arguments json.RawMessage) (string, string, error) {
+	return "", "", nil
+}
+
+func (c sendContract) Call(wallet *Wallet, method string, arguments json.RawMessage) (*string, error) {
+	if method == "derived" {
+		dw, err := wallet.DeriveAccount(1)
+		if err != nil {
+			return nil, err
+		}
+		wallet = dw
+	}
+	return wallet.Send(contract.NewTxArgs().WithDestination(c.to).WithAmount(1000))
+}
+
+// closingContract wipes the wallet it is called with
+type closingContract struct {
+	recordedContract
+}
+
+func (c closingContract) Call(wallet *Wallet, method string, arguments json.RawMessage) (*string, error) {
+	hash, err := c.recordedContract.Call(wallet, method, arguments)
+	wallet.Close()
+	return hash, err
+}
+
+type resultContract struct {
+	recordedContract
+}
+
+func (c resultContract) CallWithResult(wallet *Wallet, method string, arguments json.RawMessage) (*CallResult, error) {
+	return &CallResult{Hash: c.hash, Fee: 2000}, nil
+}
+
+func TestNewCallResult(t *testing.T) {
+	source := tezos.MustParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	dest := tezos.MustParseAddress("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX")
+
+	op := codec.NewOp().WithTransfer(dest, 1000)
+	op.WithBranch(tezos.MustParseBlockHash("BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"))
+	op.WithLimits([]tezos.Limits{{Fee: 500, GasLimit: 2000, StorageLimit: 100}}, 0)
+
+	tx := &rpc.Transaction{Destination: dest, Amount: 1000}
+	tx.Source = source
+	tx.Metadata.Result = rpc.OperationResult{
+		Status:              tezos.OpStatusApplied,
+		ConsumedMilliGas:    1500000,
+		PaidStorageSizeDiff: 67,
+		BalanceUpdates: rpc.BalanceUpdates{
+			{Kind: "contract", Contract: source, Change: -1000},
+			{Kind: "contract", Contract: dest, Change: 1000},
+			{Kind: "contract", Contract: source, Change: -16750},
+			{Kind: "burned", Category: "storage fees", Change: 16750},
+		},
+	}
+	sim := &rpc.Receipt{Op: &rpc.Operation{Contents: rpc.OperationList{tx}}}
+
+	res := newCallResult("ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE", op, sim)
+	assert.EqualValues(t, "ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE", res.Hash)
+	assert.EqualValues(t, len(op.Bytes()), res.Size)
+	assert.EqualValues(t, 500, res.Fee)
+	assert.EqualValues(t, 2000, res.GasLimit)
+	assert.EqualValues(t, 100, res.StorageLimit)
+	assert.EqualValues(t, 1500, res.GasUsed)
+	assert.EqualValues(t, 67, res.StorageUsed)
+	assert.EqualValues(t, 16750, res.Burn)
+	assert.Len(t, res.BalanceUpdates, 4)
+	assert.EqualValues(t, BalanceUpdate{Kind: "contract", Address: "KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX", Change: 1000}, res.BalanceUpdates[1])
+	assert.EqualValues(t, BalanceUpdate{Kind: "burned", Category: "storage fees", Change: 16750}, res.BalanceUpdates[3])
+}
+
+func TestCallContract(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	hash := "ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE"
+	res, err := CallContract(recordedContract{hash: hash}, w, "mint", nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, CallResult{Hash: hash, Fee: 1000}, *res)
+	// the wallet itself does not record
+	assert.Nil(t, w.recorder)
+
+	// closing the recording copy keeps the master key of the wallet
+	_, err = CallContract(closingContract{recordedContract{hash: hash}}, w, "mint", nil)
+	assert.Nil(t, err)
+	_, err = w.DeriveAccount(1)
+	assert.Nil(t, err)
+
+	res, err = CallContract(resultContract{recordedContract{hash: hash}}, w, "mint", nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2000, res.Fee)
+
+	_, err = w.SendWithResult(contract.NewTxArgs())
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+	_, err = w.BatchTransferXTZWithResult([]TransferXTZParam{{To: "tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa", Amount: 1}})
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+}
+
+func TestCallContractSend(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	dest := tezos.MustParseAddress("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	hash := "ooBghN2ok5EpgEuMqYWqvfwNLBiK9eNFoPai91iwqk2nRCyUKgE"
+
+	var injected int
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/hash"):
+			_, _ = rw.Write([]byte(`"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"`))
+		case strings.Contains(r.URL.Path, "/context/raw/json/contracts/index/"):
+			_, _ = rw.Write([]byte(`{"balance":"10000000","counter":"42","manager":"` + w.privateKey.Public().String() + `"}`))
+		case strings.HasSuffix(r.URL.Path, "/helpers/scripts/run_operation"):
+			var req struct {
+				Operation struct {
+					Contents []struct {
+						Source string `json:"source"`
+					} `json:"contents"`
+				} `json:"operation"`
+			}
+			b, _ := io.ReadAll(r.Body)
+			assert.Nil(t, json.Unmarshal(b, &req))
+			_, _ = rw.Write([]byte(fmt.Sprintf(`{"contents":[{"kind":"transaction","source":"%[1]s","fee":"0",`+
+				`"counter":"43","gas_limit":"10000","storage_limit":"100","amount":"1000","destination":"%[2]s",`+
+				`"metadata":{"operation_result":{"status":"applied","consumed_milligas":"1500000","balance_updates":[`+
+				`{"kind":"contract","contract":"%[1]s","change":"-1000","origin":"block"},`+
+				`{"kind":"contract","contract":"%[2]s","change":"1000","origin":"block"}]}}}]}`,
+				req.Operation.Contents[0].Source, dest)))
+		case r.URL.Path == "/injection/operation":
+			injected++
+			_, _ = rw.Write([]byte(`"` + hash + `"`))
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	defer node.Close()
+	c, err := rpc.NewClient(node.URL, nil)
+	assert.Nil(t, err)
+	w.rpcClient = c
+
+	res, err := CallContract(sendContract{to: dest}, w, "transfer", nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, injected)
+	assert.EqualValues(t, hash, res.Hash)
+	assert.EqualValues(t, 1500, res.GasUsed)
+	assert.True(t, res.Fee > 0)
+	assert.True(t, res.GasLimit >= res.GasUsed)
+	assert.EqualValues(t, []BalanceUpdate{
+		{Kind: "contract", Address: w.Account(), Change: -1000},
+		{Kind: "contract", Address: dest.String(), Change: 1000},
+	}, res.BalanceUpdates)
+
+	// the sends of a derived account are recorded too
+	res, err = CallContract(sendContract{to: dest}, w, "derived", nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, injected)
+	assert.EqualValues(t, hash, res.Hash)
+	assert.NotEqualValues(t, w.Account(), res.BalanceUpdates[0].Address)
+}
+
+func TestResultRecorder(t *testing.T) {
+	var r resultRecorder
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.record(CallResult{Hash: fmt.Sprint(i), Fee: int64(i)})
+			_, _ = r.result(fmt.Sprint(i))
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, r.results, 10)
+
+	res, ok := r.result("3")
+	assert.True(t, ok)
+	assert.EqualValues(t, 3, res.Fee)
+	_, ok = r.result("10")
+	assert.False(t, ok)
+}
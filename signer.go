@@ -0,0 +1,112 @@
+package tezos
+
+import (
+	"context"
+	"sync"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/signer"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+// Signer is the signing backend of a wallet. Besides the operation signing
+// defined by tzgo, it must be able to sign arbitrary bytes so that the wallet
+// can produce off-chain message signatures.
+type Signer interface {
+	signer.Signer
+
+	// SignBytes signs the blake2b digest of data with the key of address
+	SignBytes(ctx context.Context, address tezos.Address, data []byte) (tezos.Signature, error)
+}
+
+var _ Signer = (*KeySigner)(nil)
+
+// KeySigner is an in-memory signer which holds a single private key.
+// Once it is wiped, it refuses to sign.
+type KeySigner struct {
+	*signer.MemorySigner
+	key   tezos.PrivateKey
+	mu    sync.RWMutex
+	wiped bool
+}
+
+// NewKeySigner creates an in-memory signer for a given private key
+func NewKeySigner(key tezos.PrivateKey) *KeySigner {
+	return &KeySigner{
+		MemorySigner: signer.NewFromKey(key),
+		key:          key,
+	}
+}
+
+// ListAddresses returns the address of the private key
+func (s *KeySigner) ListAddresses(ctx context.Context) ([]tezos.Address, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return nil, ErrKeyWiped
+	}
+	return s.MemorySigner.ListAddresses(ctx)
+}
+
+// GetKey returns the public key of the private key
+func (s *KeySigner) GetKey(ctx context.Context, address tezos.Address) (tezos.Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return tezos.InvalidKey, ErrKeyWiped
+	}
+	return s.MemorySigner.GetKey(ctx, address)
+}
+
+// SignMessage signs a message with the private key
+func (s *KeySigner) SignMessage(ctx context.Context, address tezos.Address, msg string) (tezos.Signature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return tezos.InvalidSignature, ErrKeyWiped
+	}
+	return s.MemorySigner.SignMessage(ctx, address, msg)
+}
+
+// SignOperation signs an operation with the private key
+func (s *KeySigner) SignOperation(ctx context.Context, address tezos.Address, op *codec.Op) (tezos.Signature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return tezos.InvalidSignature, ErrKeyWiped
+	}
+	return s.MemorySigner.SignOperation(ctx, address, op)
+}
+
+// SignBlock signs a block header with the private key
+func (s *KeySigner) SignBlock(ctx context.Context, address tezos.Address, head *codec.BlockHeader) (tezos.Signature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return tezos.InvalidSignature, ErrKeyWiped
+	}
+	return s.MemorySigner.SignBlock(ctx, address, head)
+}
+
+// SignBytes signs the blake2b digest of data with the private key
+func (s *KeySigner) SignBytes(_ context.Context, address tezos.Address, data []byte) (tezos.Signature, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.wiped {
+		return tezos.InvalidSignature, ErrKeyWiped
+	}
+	if !s.key.Address().Equal(address) {
+		return tezos.InvalidSignature, signer.ErrAddressMismatch
+	}
+	d := tezos.Digest(data)
+	return s.key.Sign(d[:])
+}
+
+// Wipe zeroes the private key. The signer refuses to sign afterwards.
+func (s *KeySigner) Wipe() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the memory signer shares the key bytes
+	wipeBytes(s.key.Data)
+	s.wiped = true
+}
@@ -0,0 +1,133 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/rpc"
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+// AuthenticationTag is the prefix of the bytes signed by an authorized key
+// when a remote signer requires request authentication
+const AuthenticationTag = 0x04
+
+var _ tezos.Signer = (*Client)(nil)
+
+// Client is a signer which speaks the Octez remote signer http protocol
+type Client struct {
+	c     *rpc.Client
+	addrs []tz.Address
+	auth  tz.PrivateKey
+}
+
+// NewClient creates a remote signer client for a given signer url. An optional
+// http client can be provided, otherwise the http.DefaultClient is used.
+func NewClient(signerURL string, client *http.Client) (*Client, error) {
+	c, err := rpc.NewClient(signerURL, client)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{c: c}, nil
+}
+
+// WithAddress adds an address which is managed by the remote signer
+func (s *Client) WithAddress(addr tz.Address) *Client {
+	s.addrs = append(s.addrs, addr)
+	return s
+}
+
+// WithAuthKey sets the key used to authenticate signing requests
+func (s *Client) WithAuthKey(key tz.PrivateKey) *Client {
+	s.auth = key
+	return s
+}
+
+// AuthorizedKeys returns the addresses the remote signer accepts for authenticating requests
+func (s *Client) AuthorizedKeys(ctx context.Context) ([]tz.Address, error) {
+	var resp authorizedKeysResponse
+	if err := s.c.Get(ctx, "/authorized_keys", &resp); err != nil {
+		return nil, err
+	}
+	return resp.AuthorizedKeys, nil
+}
+
+// ListAddresses returns the addresses configured for the client
+func (s *Client) ListAddresses(_ context.Context) ([]tz.Address, error) {
+	return s.addrs, nil
+}
+
+// GetKey returns the public key of an address from the remote signer
+func (s *Client) GetKey(ctx context.Context, address tz.Address) (tz.Key, error) {
+	var resp publicKeyResponse
+	if err := s.c.Get(ctx, "/keys/"+address.String(), &resp); err != nil {
+		return tz.InvalidKey, err
+	}
+	return resp.PublicKey, nil
+}
+
+// SignBytes asks the remote signer to sign the blake2b digest of data
+func (s *Client) SignBytes(ctx context.Context, address tz.Address, data []byte) (tz.Signature, error) {
+	path := "/keys/" + address.String()
+	if s.auth.IsValid() {
+		d := tz.Digest(authenticationBytes(address, data))
+		sig, err := s.auth.Sign(d[:])
+		if err != nil {
+			return tz.InvalidSignature, err
+		}
+		path += "?" + url.Values{"authentication": {sig.String()}}.Encode()
+	}
+
+	var resp signatureResponse
+	if err := s.c.Post(ctx, path, tz.HexBytes(data), &resp); err != nil {
+		return tz.InvalidSignature, err
+	}
+	return resp.Signature, nil
+}
+
+// SignMessage signs a text message wrapped into a failing noop operation
+// with zero branch hash, so the signed bytes can never be a valid operation
+func (s *Client) SignMessage(ctx context.Context, address tz.Address, msg string) (tz.Signature, error) {
+	buf := bytes.NewBuffer([]byte{codec.OperationWatermark})
+	buf.Write(tz.ZeroBlockHash.Bytes())
+	noop := codec.FailingNoop{
+		Arbitrary: msg,
+	}
+	if err := noop.EncodeBuffer(buf, tz.DefaultParams); err != nil {
+		return tz.InvalidSignature, err
+	}
+	return s.SignBytes(ctx, address, buf.Bytes())
+}
+
+// SignOperation signs the watermarked bytes of an operation
+func (s *Client) SignOperation(ctx context.Context, address tz.Address, op *codec.Op) (tz.Signature, error) {
+	return s.SignBytes(ctx, address, op.WatermarkedBytes())
+}
+
+// SignBlock signs the watermarked bytes of a block header
+func (s *Client) SignBlock(ctx context.Context, address tz.Address, head *codec.BlockHeader) (tz.Signature, error) {
+	return s.SignBytes(ctx, address, head.WatermarkedBytes())
+}
+
+// authenticationBytes returns the bytes an authorized key signs for a request
+func authenticationBytes(address tz.Address, data []byte) []byte {
+	b := append([]byte{AuthenticationTag}, address.Encode()...)
+	return append(b, data...)
+}
+
+type authorizedKeysResponse struct {
+	AuthorizedKeys []tz.Address `json:"authorized_keys,omitempty"`
+}
+
+type publicKeyResponse struct {
+	PublicKey tz.Key `json:"public_key"`
+}
+
+type signatureResponse struct {
+	Signature tz.Signature `json:"signature"`
+}
@@ -0,0 +1,93 @@
+package remote
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"blockwatch.cc/tzgo/codec"
+	tz "blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	key, err := tz.GenerateKey(tz.KeyTypeEd25519)
+	assert.Nil(t, err)
+
+	s, err := NewServer(tezos.NewKeySigner(key))
+	assert.Nil(t, err)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, nil)
+	assert.Nil(t, err)
+	c.WithAddress(key.Address())
+
+	addrs, err := c.ListAddresses(ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []tz.Address{key.Address()}, addrs)
+
+	pk, err := c.GetKey(ctx, key.Address())
+	assert.Nil(t, err)
+	assert.True(t, pk.IsEqual(key.Public()))
+
+	data := []byte{0x05, 0x01, 0x02}
+	sig, err := c.SignBytes(ctx, key.Address(), data)
+	assert.Nil(t, err)
+	d := tz.Digest(data)
+	assert.Nil(t, pk.Verify(d[:], sig))
+
+	op := codec.NewOp().
+		WithBranch(tz.NewBlockHash([]byte{0x01})).
+		WithContents(&codec.FailingNoop{Arbitrary: "hello"})
+	sig, err = c.SignOperation(ctx, key.Address(), op)
+	assert.Nil(t, err)
+	assert.Nil(t, pk.Verify(op.Digest(), sig))
+
+	_, err = c.SignMessage(ctx, key.Address(), "hello")
+	assert.Nil(t, err)
+
+	other, err := tz.GenerateKey(tz.KeyTypeEd25519)
+	assert.Nil(t, err)
+	_, err = c.GetKey(ctx, other.Address())
+	assert.NotNil(t, err)
+	_, err = c.SignBytes(ctx, other.Address(), data)
+	assert.NotNil(t, err)
+}
+
+func TestRemoteSignerAuthentication(t *testing.T) {
+	ctx := context.Background()
+	key, err := tz.GenerateKey(tz.KeyTypeEd25519)
+	assert.Nil(t, err)
+	auth, err := tz.GenerateKey(tz.KeyTypeEd25519)
+	assert.Nil(t, err)
+
+	s, err := NewServer(tezos.NewKeySigner(key))
+	assert.Nil(t, err)
+	s.WithAuthorizedKey(auth.Public()).WithMagicBytes(0x03, 0x05)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, nil)
+	assert.Nil(t, err)
+	c.WithAddress(key.Address())
+
+	keys, err := c.AuthorizedKeys(ctx)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []tz.Address{auth.Address()}, keys)
+
+	// unauthenticated request
+	_, err = c.SignBytes(ctx, key.Address(), []byte{0x05, 0x00})
+	assert.NotNil(t, err)
+
+	c.WithAuthKey(auth)
+	_, err = c.SignBytes(ctx, key.Address(), []byte{0x05, 0x00})
+	assert.Nil(t, err)
+
+	// magic byte is not allowed
+	_, err = c.SignBytes(ctx, key.Address(), []byte{0x01, 0x00})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,187 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+var (
+	ErrUnknownAddress      = errors.New("Address is not managed by this signer")
+	ErrInvalidRequest      = errors.New("Invalid signing request")
+	ErrUnauthorizedRequest = errors.New("Signing request is not authorized")
+	ErrMagicByteNotAllowed = errors.New("Magic byte of data is not allowed")
+	ErrDuplicatedAddress   = errors.New("Duplicated signer address")
+)
+
+// Server exposes signers over the Octez remote signer http protocol
+type Server struct {
+	signers    map[string]tezos.Signer
+	authKeys   []tz.Key
+	magicBytes []byte
+}
+
+// NewServer creates a remote signer server for the addresses managed by the given signers
+func NewServer(signers ...tezos.Signer) (*Server, error) {
+	s := &Server{
+		signers: map[string]tezos.Signer{},
+	}
+	for _, sg := range signers {
+		addrs, err := sg.ListAddresses(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			if _, ok := s.signers[a.String()]; ok {
+				return nil, ErrDuplicatedAddress
+			}
+			s.signers[a.String()] = sg
+		}
+	}
+	return s, nil
+}
+
+// NewWalletServer creates a remote signer server for the wallet account and
+// the derived accounts of the given indexes
+func NewWalletServer(w *tezos.Wallet, indexes ...uint) (*Server, error) {
+	signers := []tezos.Signer{w.Signer()}
+	for _, i := range indexes {
+		if i == w.AccountIndex() {
+			continue
+		}
+		s, err := w.DeriveSigner(i)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, s)
+	}
+	return NewServer(signers...)
+}
+
+// WithAuthorizedKey requires signing requests to be authenticated by one of the authorized keys
+func (s *Server) WithAuthorizedKey(key tz.Key) *Server {
+	s.authKeys = append(s.authKeys, key)
+	return s
+}
+
+// WithMagicBytes restricts the first byte of the data which is allowed to be signed
+func (s *Server) WithMagicBytes(b ...byte) *Server {
+	s.magicBytes = append(s.magicBytes, b...)
+	return s
+}
+
+// ServeHTTP handles the remote signer http requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/authorized_keys" && r.Method == http.MethodGet:
+		s.handleAuthorizedKeys(w, r)
+	case strings.HasPrefix(r.URL.Path, "/keys/") && r.Method == http.MethodGet:
+		s.handleGetKey(w, r)
+	case strings.HasPrefix(r.URL.Path, "/keys/") && r.Method == http.MethodPost:
+		s.handleSign(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleAuthorizedKeys(w http.ResponseWriter, _ *http.Request) {
+	var resp authorizedKeysResponse
+	for _, k := range s.authKeys {
+		resp.AuthorizedKeys = append(resp.AuthorizedKeys, k.Address())
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleGetKey(w http.ResponseWriter, r *http.Request) {
+	addr, sg, err := s.lookup(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	k, err := sg.GetKey(r.Context(), addr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, publicKeyResponse{PublicKey: k})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	addr, sg, err := s.lookup(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var data tz.HexBytes
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil || len(data) == 0 {
+		http.Error(w, ErrInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(s.magicBytes) > 0 && !containsByte(s.magicBytes, data[0]) {
+		http.Error(w, ErrMagicByteNotAllowed.Error(), http.StatusForbidden)
+		return
+	}
+
+	if len(s.authKeys) > 0 && !s.isAuthorized(addr, data, r.URL.Query().Get("authentication")) {
+		http.Error(w, ErrUnauthorizedRequest.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	sig, err := sg.SignBytes(r.Context(), addr, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, signatureResponse{Signature: sig})
+}
+
+// lookup returns the address in the request path and the signer which manages it
+func (s *Server) lookup(r *http.Request) (tz.Address, tezos.Signer, error) {
+	addr, err := tz.ParseAddress(strings.TrimPrefix(r.URL.Path, "/keys/"))
+	if err != nil {
+		return tz.InvalidAddress, nil, ErrUnknownAddress
+	}
+	sg, ok := s.signers[addr.String()]
+	if !ok {
+		return tz.InvalidAddress, nil, ErrUnknownAddress
+	}
+	return addr, sg, nil
+}
+
+// isAuthorized verifies the authentication signature against the authorized keys
+func (s *Server) isAuthorized(addr tz.Address, data []byte, authentication string) bool {
+	sig, err := tz.ParseSignature(authentication)
+	if err != nil {
+		return false
+	}
+	d := tz.Digest(authenticationBytes(addr, data))
+	for _, k := range s.authKeys {
+		if k.IsValid() && k.Verify(d[:], sig) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func containsByte(bs []byte, b byte) bool {
+	for _, v := range bs {
+		if v == b {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
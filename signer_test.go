@@ -0,0 +1,31 @@
+package tezos
+
+import (
+	"context"
+	"testing"
+
+	"blockwatch.cc/tzgo/signer"
+	"blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySignerSignBytes(t *testing.T) {
+	key, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	assert.Nil(t, err)
+	s := NewKeySigner(key)
+
+	addrs, err := s.ListAddresses(context.Background())
+	assert.Nil(t, err)
+	assert.EqualValues(t, []tezos.Address{key.Address()}, addrs)
+
+	data := []byte("hello")
+	sig, err := s.SignBytes(context.Background(), key.Address(), data)
+	assert.Nil(t, err)
+	d := tezos.Digest(data)
+	assert.Nil(t, key.Public().Verify(d[:], sig))
+
+	other, err := tezos.GenerateKey(tezos.KeyTypeEd25519)
+	assert.Nil(t, err)
+	_, err = s.SignBytes(context.Background(), other.Address(), data)
+	assert.EqualError(t, err, signer.ErrAddressMismatch.Error())
+}
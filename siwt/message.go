@@ -0,0 +1,126 @@
+package siwt
+
+import (
+	"strings"
+	"time"
+
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+// MessageVersion is the version of the login message format
+const MessageVersion = "1"
+
+const (
+	headerSuffix        = " wants you to sign in with your Tezos account:"
+	uriField            = "URI: "
+	versionField        = "Version: "
+	chainIDField        = "Chain ID: "
+	nonceField          = "Nonce: "
+	issuedAtField       = "Issued At: "
+	expirationTimeField = "Expiration Time: "
+)
+
+// Message is a Sign-In-With-Tezos login message
+type Message struct {
+	Domain         string    `json:"domain"`
+	Address        string    `json:"address"`
+	Statement      string    `json:"statement,omitempty"`
+	URI            string    `json:"uri,omitempty"`
+	Version        string    `json:"version"`
+	ChainID        string    `json:"chain_id"`
+	Nonce          string    `json:"nonce"`
+	IssuedAt       time.Time `json:"issued_at"`
+	ExpirationTime time.Time `json:"expiration_time,omitempty"`
+}
+
+// String returns the human readable text of the message which is shown by the wallet
+func (m Message) String() string {
+	var b strings.Builder
+	b.WriteString(m.Domain + headerSuffix + "\n")
+	b.WriteString(m.Address + "\n")
+	if m.Statement != "" {
+		b.WriteString("\n" + m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	if m.URI != "" {
+		b.WriteString(uriField + m.URI + "\n")
+	}
+	b.WriteString(versionField + m.Version + "\n")
+	b.WriteString(chainIDField + m.ChainID + "\n")
+	b.WriteString(nonceField + m.Nonce + "\n")
+	b.WriteString(issuedAtField + m.IssuedAt.UTC().Format(time.RFC3339))
+	if !m.ExpirationTime.IsZero() {
+		b.WriteString("\n" + expirationTimeField + m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// Payload encodes the message in the Micheline sign payload format of the wallets
+func (m Message) Payload() []byte {
+	return tezos.FormatSignPayload(m.Domain, m.IssuedAt, m.String())
+}
+
+// ParseMessage parses the text of a login message
+func ParseMessage(text string) (*Message, error) {
+	lines := strings.Split(text, "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[0], headerSuffix) {
+		return nil, ErrInvalidMessage
+	}
+
+	m := &Message{
+		Domain:  strings.TrimSuffix(lines[0], headerSuffix),
+		Address: lines[1],
+	}
+	if _, err := tz.ParseAddress(m.Address); err != nil {
+		return nil, ErrInvalidMessage
+	}
+
+	var statement []string
+	for _, l := range lines[2:] {
+		var err error
+		switch {
+		case strings.HasPrefix(l, uriField):
+			m.URI = strings.TrimPrefix(l, uriField)
+		case strings.HasPrefix(l, versionField):
+			m.Version = strings.TrimPrefix(l, versionField)
+		case strings.HasPrefix(l, chainIDField):
+			m.ChainID = strings.TrimPrefix(l, chainIDField)
+		case strings.HasPrefix(l, nonceField):
+			m.Nonce = strings.TrimPrefix(l, nonceField)
+		case strings.HasPrefix(l, issuedAtField):
+			m.IssuedAt, err = time.Parse(time.RFC3339, strings.TrimPrefix(l, issuedAtField))
+		case strings.HasPrefix(l, expirationTimeField):
+			m.ExpirationTime, err = time.Parse(time.RFC3339, strings.TrimPrefix(l, expirationTimeField))
+		case l != "":
+			statement = append(statement, l)
+		}
+		if err != nil {
+			return nil, ErrInvalidMessage
+		}
+	}
+	m.Statement = strings.Join(statement, "\n")
+
+	if m.Domain == "" || m.Version != MessageVersion || m.ChainID == "" || m.Nonce == "" || m.IssuedAt.IsZero() {
+		return nil, ErrInvalidMessage
+	}
+	return m, nil
+}
+
+// validate checks the message against the expected domain and chain at a time
+func (m Message) validate(domain, chainID string, now time.Time) error {
+	if m.Domain != domain {
+		return ErrDomainMismatch
+	}
+	if m.ChainID != chainID {
+		return ErrChainIDMismatch
+	}
+	if !m.ExpirationTime.IsZero() && !now.Before(m.ExpirationTime) {
+		return ErrMessageExpired
+	}
+	if m.IssuedAt.After(now.Add(maxClockSkew)) {
+		return ErrMessageNotYetValid
+	}
+	return nil
+}
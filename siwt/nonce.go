@@ -0,0 +1,60 @@
+package siwt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore keeps the issued nonces of the login messages. A nonce can only
+// be consumed once, which protects the login against replays. Implementations
+// must be safe for concurrent use.
+type NonceStore interface {
+	// Add stores a nonce which is valid until the expiry
+	Add(ctx context.Context, nonce string, expiry time.Time) error
+	// Consume removes a nonce and reports whether it was issued and not expired
+	Consume(ctx context.Context, nonce string) (bool, error)
+}
+
+var _ NonceStore = (*MemoryNonceStore)(nil)
+
+// MemoryNonceStore is an in-memory nonce store for a single server
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: map[string]time.Time{},
+	}
+}
+
+// Add stores a nonce and drops the expired ones
+func (s *MemoryNonceStore) Add(_ context.Context, nonce string, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for n, e := range s.nonces {
+		if !now.Before(e) {
+			delete(s.nonces, n)
+		}
+	}
+	s.nonces[nonce] = expiry
+	return nil
+}
+
+// Consume removes a nonce and reports whether it was issued and not expired
+func (s *MemoryNonceStore) Consume(_ context.Context, nonce string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.nonces[nonce]
+	if !ok {
+		return false, nil
+	}
+	delete(s.nonces, nonce)
+	return time.Now().Before(e), nil
+}
@@ -0,0 +1,147 @@
+package siwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	tz "blockwatch.cc/tzgo/tezos"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+const (
+	// DefaultMessageTTL is the default validity of a login message
+	DefaultMessageTTL = 10 * time.Minute
+	// maxClockSkew is the tolerated clock difference between the issuer and the verifier
+	maxClockSkew = time.Minute
+	nonceSize    = 16
+)
+
+var (
+	ErrInvalidMessage     = errors.New("Invalid login message")
+	ErrInvalidAddress     = errors.New("Invalid address provided")
+	ErrAddressMismatch    = errors.New("Public key does not match the message address")
+	ErrDomainMismatch     = errors.New("Message domain does not match")
+	ErrChainIDMismatch    = errors.New("Message chain ID does not match")
+	ErrMessageExpired     = errors.New("Login message is expired")
+	ErrMessageNotYetValid = errors.New("Login message is not valid yet")
+	ErrInvalidNonce       = errors.New("Nonce is unknown or has been used")
+)
+
+// Authenticator implements Sign-In-With-Tezos for a domain. It issues login
+// messages with a one-time nonce, the user signs them with a Tezos wallet, and
+// it verifies the returned signatures.
+type Authenticator struct {
+	domain    string
+	uri       string
+	statement string
+	chainID   string
+	ttl       time.Duration
+	nonces    NonceStore
+	now       func() time.Time
+}
+
+// NewAuthenticator creates an authenticator for a domain. The login messages
+// are bound to the chain of the wallet.
+func NewAuthenticator(w *tezos.Wallet, domain string, nonces NonceStore) *Authenticator {
+	return &Authenticator{
+		domain:  domain,
+		chainID: w.ChainID(),
+		ttl:     DefaultMessageTTL,
+		nonces:  nonces,
+		now:     time.Now,
+	}
+}
+
+// WithURI sets the URI of the resource the user signs in to
+func (a *Authenticator) WithURI(uri string) *Authenticator {
+	a.uri = uri
+	return a
+}
+
+// WithStatement sets the statement which is shown to the user
+func (a *Authenticator) WithStatement(statement string) *Authenticator {
+	a.statement = statement
+	return a
+}
+
+// WithTTL sets the validity of the login messages
+func (a *Authenticator) WithTTL(ttl time.Duration) *Authenticator {
+	a.ttl = ttl
+	return a
+}
+
+// NewMessage issues a login message with a new nonce for an address
+func (a *Authenticator) NewMessage(address string) (*Message, error) {
+	addr, err := tz.ParseAddress(address)
+	if err != nil || !addr.IsEOA() {
+		return nil, ErrInvalidAddress
+	}
+
+	b := make([]byte, nonceSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	now := a.now().UTC().Truncate(time.Second)
+	m := &Message{
+		Domain:         a.domain,
+		Address:        addr.String(),
+		Statement:      a.statement,
+		URI:            a.uri,
+		Version:        MessageVersion,
+		ChainID:        a.chainID,
+		Nonce:          hex.EncodeToString(b),
+		IssuedAt:       now,
+		ExpirationTime: now.Add(a.ttl),
+	}
+	if err := a.nonces.Add(context.Background(), m.Nonce, m.ExpirationTime); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Verify verifies a signed login payload with the public key of the user and
+// consumes its nonce. It returns the verified message.
+func (a *Authenticator) Verify(payload []byte, publicKey, signature string) (*Message, error) {
+	p, err := tezos.ParseSignPayload(payload)
+	if err != nil || p.Type != tezos.SignPayloadMicheline {
+		return nil, ErrInvalidMessage
+	}
+	m, err := ParseMessage(p.Message)
+	if err != nil {
+		return nil, err
+	}
+	// the payload header must match the message
+	if p.DAppURL != m.Domain || !p.Timestamp.Equal(m.IssuedAt) {
+		return nil, ErrInvalidMessage
+	}
+
+	if err := m.validate(a.domain, a.chainID, a.now()); err != nil {
+		return nil, err
+	}
+
+	key, err := tz.ParseKey(publicKey)
+	if err != nil {
+		return nil, tezos.ErrInvalidPublicKey
+	}
+	if key.Address().String() != m.Address {
+		return nil, ErrAddressMismatch
+	}
+	if err := tezos.VerifySignPayload(publicKey, payload, signature); err != nil {
+		return nil, err
+	}
+
+	// consume the nonce only for a valid signature, so nobody else can burn it
+	ok, err := a.nonces.Consume(context.Background(), m.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidNonce
+	}
+	return m, nil
+}
@@ -0,0 +1,100 @@
+package siwt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tz "blockwatch.cc/tzgo/tezos"
+	"github.com/stretchr/testify/assert"
+
+	tezos "github.com/bitmark-inc/account-vault-tezos"
+)
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	key, err := tz.GenerateKey(tz.KeyTypeEd25519)
+	assert.Nil(t, err)
+	w, err := tezos.NewOfflineWalletWithSigner(tezos.NewKeySigner(key), "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+	return NewAuthenticator(w, "feralfile.com", NewMemoryNonceStore()).
+		WithURI("https://feralfile.com/login").
+		WithStatement("Sign in to Feral File")
+}
+
+func TestMessage(t *testing.T) {
+	a := newTestAuthenticator(t)
+	m, err := a.NewMessage("tz1b4FWeKgXysDkeeHMaxy516PXB3Lni6Rpa")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "NetXdQprcVkpaWU", m.ChainID)
+	assert.Len(t, m.Nonce, 32)
+	assert.EqualValues(t, DefaultMessageTTL, m.ExpirationTime.Sub(m.IssuedAt))
+
+	pm, err := ParseMessage(m.String())
+	assert.Nil(t, err)
+	assert.EqualValues(t, m, pm)
+
+	p, err := tezos.ParseSignPayload(m.Payload())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "feralfile.com", p.DAppURL)
+	assert.EqualValues(t, m.String(), p.Message)
+
+	_, err = ParseMessage("feralfile.com wants you to sign in")
+	assert.EqualError(t, err, ErrInvalidMessage.Error())
+	_, err = a.NewMessage("KT1ESGez4dEuDjjNt4k2HPAK5Nzh7e8X8jyX")
+	assert.EqualError(t, err, ErrInvalidAddress.Error())
+}
+
+func TestVerify(t *testing.T) {
+	a := newTestAuthenticator(t)
+	key, err := tz.GenerateKey(tz.KeyTypeSecp256k1)
+	assert.Nil(t, err)
+	user := tezos.NewKeySigner(key)
+	sign := func(payload []byte) string {
+		sig, err := user.SignBytes(context.Background(), key.Address(), payload)
+		assert.Nil(t, err)
+		return sig.String()
+	}
+
+	m, err := a.NewMessage(key.Address().String())
+	assert.Nil(t, err)
+	payload := m.Payload()
+	sig := sign(payload)
+
+	_, err = a.Verify(payload, key.Public().String(), sign([]byte("other")))
+	assert.EqualError(t, err, tezos.ErrSignatureMismatch.Error())
+
+	other, _ := tz.GenerateKey(tz.KeyTypeEd25519)
+	_, err = a.Verify(payload, other.Public().String(), sig)
+	assert.EqualError(t, err, ErrAddressMismatch.Error())
+
+	vm, err := a.Verify(payload, key.Public().String(), sig)
+	assert.Nil(t, err)
+	assert.EqualValues(t, m.Nonce, vm.Nonce)
+
+	// replay
+	_, err = a.Verify(payload, key.Public().String(), sig)
+	assert.EqualError(t, err, ErrInvalidNonce.Error())
+
+	// expired
+	m, err = a.NewMessage(key.Address().String())
+	assert.Nil(t, err)
+	a.now = func() time.Time { return time.Now().Add(DefaultMessageTTL) }
+	_, err = a.Verify(m.Payload(), key.Public().String(), sign(m.Payload()))
+	assert.EqualError(t, err, ErrMessageExpired.Error())
+	a.now = time.Now
+
+	// another domain or chain
+	m.Domain = "example.com"
+	_, err = a.Verify(m.Payload(), key.Public().String(), sign(m.Payload()))
+	assert.EqualError(t, err, ErrDomainMismatch.Error())
+	m.Domain = "feralfile.com"
+	m.ChainID = "NetXnHfVqm9iesp"
+	_, err = a.Verify(m.Payload(), key.Public().String(), sign(m.Payload()))
+	assert.EqualError(t, err, ErrChainIDMismatch.Error())
+
+	// unknown nonce
+	m.ChainID = "NetXdQprcVkpaWU"
+	m.Nonce = "00"
+	_, err = a.Verify(m.Payload(), key.Public().String(), sign(m.Payload()))
+	assert.EqualError(t, err, ErrInvalidNonce.Error())
+}
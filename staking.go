@@ -0,0 +1,138 @@
+package tezos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"blockwatch.cc/tzgo/codec"
+	"blockwatch.cc/tzgo/micheline"
+	"blockwatch.cc/tzgo/rpc"
+	"blockwatch.cc/tzgo/tezos"
+)
+
+const (
+	StakeEntrypoint           = "stake"
+	UnstakeEntrypoint         = "unstake"
+	FinalizeUnstakeEntrypoint = "finalize_unstake"
+)
+
+// stakingEntrypointTags are the binary tags of the staking pseudo-entrypoints.
+// The node re-encodes operations with these tags before checking signatures,
+// so they can not be encoded as named entrypoints.
+var stakingEntrypointTags = map[string]byte{
+	StakeEntrypoint:           6,
+	UnstakeEntrypoint:         7,
+	FinalizeUnstakeEntrypoint: 8,
+}
+
+// StakingBalances represents the staking state of an account in mutez
+type StakingBalances struct {
+	Staked              int64 `json:"staked"`
+	UnstakedFrozen      int64 `json:"unstaked_frozen"`
+	UnstakedFinalizable int64 `json:"unstaked_finalizable"`
+}
+
+// Stake stakes an amount of the wallet account balance with its delegate
+func (w *Wallet) Stake(amount int64) (*string, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	op, opts := w.stakingOp(StakeEntrypoint, amount)
+	return w.send(op, opts)
+}
+
+// Unstake requests an amount of the staked balance of the wallet account back.
+// The amount is frozen for a few cycles before it can be finalized.
+func (w *Wallet) Unstake(amount int64) (*string, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	op, opts := w.stakingOp(UnstakeEntrypoint, amount)
+	return w.send(op, opts)
+}
+
+// FinalizeUnstake moves the finalizable unstaked balance of the wallet account
+// back to its spendable balance
+func (w *Wallet) FinalizeUnstake() (*string, error) {
+	op, opts := w.stakingOp(FinalizeUnstakeEntrypoint, 0)
+	return w.send(op, opts)
+}
+
+// StakingBalances returns the staked, unstaked frozen and unstaked
+// finalizable balances of the wallet account
+func (w *Wallet) StakingBalances() (*StakingBalances, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
+	ctx := context.Background()
+	b := &StakingBalances{}
+	for path, v := range map[string]*int64{
+		"staked_balance":               &b.Staked,
+		"unstaked_frozen_balance":      &b.UnstakedFrozen,
+		"unstaked_finalizable_balance": &b.UnstakedFinalizable,
+	} {
+		var z tezos.Z
+		u := "chains/main/blocks/head/context/contracts/" + w.address.String() + "/" + path
+		if err := w.rpcClient.Get(ctx, u, &z); err != nil {
+			return nil, err
+		}
+		*v = z.Int64()
+	}
+	return b, nil
+}
+
+// stakingOp constructs an operation which calls a staking pseudo-entrypoint
+// with a transfer to the wallet account itself
+func (w *Wallet) stakingOp(entrypoint string, amount int64) (*codec.Op, *rpc.CallOptions) {
+	opts := &rpc.CallOptions{
+		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
+		MaxFee: 1_000_000,
+	}
+
+	op := codec.NewOp().WithTTL(opts.TTL)
+	op.WithContents(&stakingTransaction{
+		Transaction: &codec.Transaction{
+			Amount:      tezos.N(amount),
+			Destination: w.address,
+			Parameters: &micheline.Parameters{
+				Entrypoint: entrypoint,
+				Value:      micheline.NewPrim(micheline.D_UNIT),
+			},
+		},
+	})
+
+	op.WithParams(w.params())
+
+	return op, opts
+}
+
+// stakingTransaction is a transaction which encodes the staking
+// pseudo-entrypoints with their protocol tags
+type stakingTransaction struct {
+	*codec.Transaction
+}
+
+func (o stakingTransaction) EncodeBuffer(buf *bytes.Buffer, p *tezos.Params) error {
+	val, err := o.Parameters.Value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte(o.Kind().TagVersion(p.OperationTagsVersion))
+	o.Manager.EncodeBuffer(buf, p)
+	o.Amount.EncodeBuffer(buf)
+	buf.Write(o.Destination.EncodePadded())
+	buf.WriteByte(0xff)
+	buf.WriteByte(stakingEntrypointTags[o.Parameters.Entrypoint])
+	binary.Write(buf, binary.BigEndian, uint32(len(val)))
+	buf.Write(val)
+	return nil
+}
+
+func (o stakingTransaction) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	err := o.EncodeBuffer(buf, tezos.DefaultParams)
+	return buf.Bytes(), err
+}
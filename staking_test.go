@@ -0,0 +1,74 @@
+package tezos
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"blockwatch.cc/tzgo/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaking(t *testing.T) {
+	s, _ := hex.DecodeString("063cafb67a29cb2c567a4ecba7edc856a54403952272bffd492caaf9095a9442b208d9f0d2b75a7b1cda59819c245949b9d7e4826e7ace8e19a970a080707fed")
+	w, err := NewOfflineWallet(s, "NetXdQprcVkpaWU")
+	assert.Nil(t, err)
+
+	_, err = w.Stake(0)
+	assert.EqualError(t, err, ErrInvalidAmount.Error())
+	_, err = w.Unstake(-1)
+	assert.EqualError(t, err, ErrInvalidAmount.Error())
+	_, err = w.Stake(1_000_000)
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+	_, err = w.FinalizeUnstake()
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+	_, err = w.StakingBalances()
+	assert.EqualError(t, err, ErrOfflineWallet.Error())
+
+	for ep, tag := range map[string]string{
+		StakeEntrypoint:           "ff06",
+		UnstakeEntrypoint:         "ff07",
+		FinalizeUnstakeEntrypoint: "ff08",
+	} {
+		op, _ := w.stakingOp(ep, 1_000_000)
+		assert.Len(t, op.Contents, 1)
+		tx := op.Contents[0].(*stakingTransaction)
+		assert.EqualValues(t, w.address, tx.Destination)
+		assert.EqualValues(t, ep, tx.Parameters.Entrypoint)
+
+		// the entrypoint is encoded with its tag followed by the unit value
+		b, err := tx.MarshalBinary()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasSuffix(hex.EncodeToString(b), tag+"00000002030b"))
+
+		j, err := tx.MarshalJSON()
+		assert.Nil(t, err)
+		assert.Contains(t, string(j), `"entrypoint":"`+ep+`"`)
+	}
+
+	balances := map[string]string{
+		"staked_balance":               `"5000000"`,
+		"unstaked_frozen_balance":      `"1000000"`,
+		"unstaked_finalizable_balance": `null`,
+	}
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		prefix := "/chains/main/blocks/head/context/contracts/" + w.Account() + "/"
+		assert.True(t, strings.HasPrefix(r.URL.Path, prefix))
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(balances[strings.TrimPrefix(r.URL.Path, prefix)]))
+	}))
+	defer node.Close()
+	c, err := rpc.NewClient(node.URL, nil)
+	assert.Nil(t, err)
+	w.rpcClient = c
+
+	b, err := w.StakingBalances()
+	assert.Nil(t, err)
+	assert.EqualValues(t, &StakingBalances{
+		Staked:              5_000_000,
+		UnstakedFrozen:      1_000_000,
+		UnstakedFinalizable: 0,
+	}, b)
+}
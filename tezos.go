@@ -33,18 +33,62 @@ var (
 	ErrInvalidTokenID                = errors.New("Invalid tokenID provided")
 	ErrTransferAmountLowerThanSetFee = errors.New("Transfer amount lower than set fee")
 	ErrExceedSettingFee              = errors.New("Actual cost is more than setting")
+	ErrNoSignerAddress               = errors.New("Signer does not manage any address")
+	ErrMasterKeyUnavailable          = errors.New("Master key is not available for this wallet")
+	ErrUnsupportedKeyType            = errors.New("Unsupported key type")
+	ErrInvalidMnemonic               = errors.New("Invalid mnemonic provided")
+	ErrInvalidMnemonicChecksum       = errors.New("Invalid mnemonic checksum")
+	ErrInvalidKeystore               = errors.New("Invalid or unsupported keystore")
+	ErrKeystoreCorrupted             = errors.New("Keystore is corrupted")
+	ErrInvalidPassword               = errors.New("Invalid password provided")
+	ErrOfflineWallet                 = errors.New("Operation is not available for an offline wallet")
+	ErrInvalidChainID                = errors.New("Invalid chain ID provided")
+	ErrInvalidBranch                 = errors.New("Invalid branch provided")
+	ErrInvalidLimits                 = errors.New("Invalid operation limits provided")
+	ErrInvalidEnvelope               = errors.New("Invalid operation envelope")
+	ErrSourceMismatch                = errors.New("Operation source does not match the wallet account")
+	ErrMissingLimits                 = errors.New("Operation limits are required")
+	ErrEnvelopeLimitsMismatch        = errors.New("Envelope limits do not match the operation")
+	ErrEnvelopeNotSigned             = errors.New("Operation envelope is not signed")
+	ErrInvalidDerivationScheme       = errors.New("Invalid derivation path scheme")
+	ErrAccountNotFound               = errors.New("Account is not found in the derivation schemes")
+	ErrKeyWiped                      = errors.New("Key material has been wiped")
+	ErrKeyExportDisabled             = errors.New("Private key export is disabled for this wallet")
+	ErrPrivateKeyUnavailable         = errors.New("Private key is not available for this wallet")
+	ErrSignatureMismatch             = errors.New("Signature does not match the message")
+	ErrPublicKeyUnavailable          = errors.New("Public key of the address is not available")
+	ErrInvalidSignPayload            = errors.New("Invalid sign payload provided")
+	ErrUnsupportedAuthTransferFormat = errors.New("Unsupported authorized transfer format")
+	ErrInvalidAmount                 = errors.New("Invalid amount provided")
+	ErrInvalidNonce                  = errors.New("Invalid nonce provided")
+	ErrUnsupportedMichelsonType      = errors.New("Unsupported Michelson type")
+	ErrInvalidMichelsonValue         = errors.New("Invalid value for the Michelson type")
+	ErrContractNotOriginated         = errors.New("No contract is originated by the operation")
+	ErrInvalidContractScript         = errors.New("Invalid contract code or storage provided")
+	ErrStorageBurnExceeded           = errors.New("Storage burn exceeds the maximum")
+	ErrInvalidMichelsonTag           = errors.New("Invalid michelson struct tag")
+	ErrInvalidOperationHash          = errors.New("Invalid operation hash")
+	ErrOperationNotFound             = errors.New("Operation not found")
+	ErrCallResultNotFound            = errors.New("Call result not found")
 )
 
 func buildDerivePath(index uint) string {
-	return fmt.Sprintf("m/44'/1729'/%d'/0'", index)
+	return DefaultDerivationScheme.Path(index)
 }
 
 type Wallet struct {
 	chainID      tezos.ChainIdHash
-	masterKey    ed25519hd.PrivateKey
+	keyType      tezos.KeyType
+	masterKey    hdKey
+	ownsMaster   bool
+	scheme       DerivationScheme
 	privateKey   tezos.PrivateKey
+	keyExport    bool
 	accountIndex uint
+	address      tezos.Address
+	signer       Signer
 	rpcClient    *rpc.Client
+	recorder     *resultRecorder
 }
 
 type TransferXTZParam struct {
@@ -52,25 +96,104 @@ type TransferXTZParam struct {
 	Amount int64
 }
 
+// WalletOption configures the optional settings of a wallet
+type WalletOption func(*walletOptions)
+
+type walletOptions struct {
+	keyType        tezos.KeyType
+	scheme         DerivationScheme
+	derivedKeyOnly bool
+	keyExport      bool
+}
+
+func defaultWalletOptions() walletOptions {
+	return walletOptions{
+		keyType:   tezos.KeyTypeEd25519,
+		scheme:    DefaultDerivationScheme,
+		keyExport: true,
+	}
+}
+
+// WithKeyType sets the key type of the derived accounts. Ed25519 (tz1) keys are
+// derived with SLIP-10, secp256k1 (tz2) and P-256 (tz3) keys with BIP32.
+func WithKeyType(keyType tezos.KeyType) WalletOption {
+	return func(o *walletOptions) {
+		o.keyType = keyType
+	}
+}
+
 // NewWallet creates a tezos wallet from a given seed
-func NewWallet(seed []byte, network string, rpcURL string) (*Wallet, error) {
-	pk, err := ed25519hd.GetMasterKeyFromSeed(seed)
+func NewWallet(seed []byte, network string, rpcURL string, opts ...WalletOption) (*Wallet, error) {
+	o, mk, key, err := newWalletKeys(seed, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	dpk, _ := pk.DeriveChildPrivateKey(buildDerivePath(DefaultAccountIndex))
-	key := toTzgoPrivateKey(*dpk)
+	w, err := NewWalletWithSigner(NewKeySigner(key), network, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	w.setKeys(o, mk, key)
+
+	return w, nil
+}
+
+// newWalletKeys creates the master key from a seed and derives the default account key
+func newWalletKeys(seed []byte, opts []WalletOption) (walletOptions, hdKey, tezos.PrivateKey, error) {
+	o := defaultWalletOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	mk, err := newMasterKey(seed, o.keyType)
+	if err != nil {
+		return o, nil, tezos.PrivateKey{}, err
+	}
+
+	key, err := mk.derivePrivateKey(o.scheme.Path(DefaultAccountIndex))
+	if err != nil {
+		return o, nil, tezos.PrivateKey{}, err
+	}
+
+	if o.derivedKeyOnly {
+		mk.wipe()
+		mk = nil
+	}
+	return o, mk, key, nil
+}
+
+// setKeys binds the key material created by newWalletKeys to the wallet
+func (w *Wallet) setKeys(o walletOptions, mk hdKey, key tezos.PrivateKey) {
+	w.keyType = o.keyType
+	w.masterKey = mk
+	w.ownsMaster = mk != nil
+	w.scheme = o.scheme
+	w.privateKey = key
+	w.keyExport = o.keyExport
+}
+
+// NewWalletWithSigner creates a tezos wallet which signs through a given signer backend.
+// The first address managed by the signer is used as the wallet account.
+func NewWalletWithSigner(s Signer, network string, rpcURL string) (*Wallet, error) {
+	ctx := context.Background()
+
+	addrs, err := s.ListAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, ErrNoSignerAddress
+	}
 
 	c, err := rpc.NewClient(rpcURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set default signer to wallet private key
-	c.Signer = signer.NewFromKey(key)
+	// Set default signer to wallet signer
+	c.Signer = s
 
-	if err := c.Init(context.Background()); err != nil {
+	if err := c.Init(ctx); err != nil {
 		return nil, ErrInvalidRpcNode
 	}
 
@@ -83,34 +206,72 @@ func NewWallet(seed []byte, network string, rpcURL string) (*Wallet, error) {
 
 	return &Wallet{
 		chainID:      c.ChainId,
-		masterKey:    *pk,
-		privateKey:   key,
+		keyType:      addrs[0].KeyType(),
 		accountIndex: DefaultAccountIndex,
+		address:      addrs[0],
+		signer:       s,
 		rpcClient:    c,
 	}, nil
 }
 
-// DeriveAccount derive the specific index account from the master key
+// DeriveAccount derive the specific index account from the master key. The derived
+// account has its own signer and rpc client, while the http connections are shared.
+// The results of its sends are recorded like those of the wallet.
 func (w *Wallet) DeriveAccount(index uint) (*Wallet, error) {
-	dpk, err := w.masterKey.DeriveChildPrivateKey(buildDerivePath(index))
+	s, err := w.DeriveSigner(index)
 	if err != nil {
 		return nil, err
 	}
-	key := toTzgoPrivateKey(*dpk)
-	rpc := w.rpcClient
-	rpc.Signer = signer.NewFromKey(key)
+	key := s.key
+
+	// copy the rpc client so the signer of other accounts is untouched
+	var c *rpc.Client
+	if w.rpcClient != nil {
+		rc := *w.rpcClient
+		rc.Signer = s
+		c = &rc
+	}
 
 	return &Wallet{
 		chainID:      w.chainID,
+		keyType:      w.keyType,
 		masterKey:    w.masterKey,
+		scheme:       w.scheme,
 		privateKey:   key,
+		keyExport:    w.keyExport,
 		accountIndex: index,
-		rpcClient:    rpc,
+		address:      key.Address(),
+		signer:       s,
+		rpcClient:    c,
+		recorder:     w.recorder,
 	}, nil
 }
 
-// signMessage sign a specific message from privateKey
+// DeriveSigner derive the specific index account from the master key and returns
+// an in-memory signer for it
+func (w *Wallet) DeriveSigner(index uint) (*KeySigner, error) {
+	if w.masterKey == nil {
+		return nil, ErrMasterKeyUnavailable
+	}
+
+	key, err := w.masterKey.derivePrivateKey(w.scheme.Path(index))
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key), nil
+}
+
+// signMessage sign a specific message with the wallet signer
 func (w *Wallet) signMessage(message []byte) (string, error) {
+	sig, err := w.signer.SignBytes(context.Background(), w.address, messagePayload(message))
+	if err != nil {
+		return "", ErrSignFailed
+	}
+	return sig.Generic(), nil
+}
+
+// messagePayload returns the packed bytes of a message which are signed by signMessage
+func messagePayload(message []byte) []byte {
 	// force add prefix to message to prevent possible attack
 	m := append([]byte(DefaultSignPrefix), message...)
 	// pack the message to tezos bytes
@@ -118,34 +279,38 @@ func (w *Wallet) signMessage(message []byte) (string, error) {
 		Type:  micheline.PrimBytes,
 		Bytes: m,
 	}
-	dm := tezos.Digest(mp.Pack())
-	sig, err := w.privateKey.Sign(dm[:])
+	return mp.Pack()
+}
+
+// SignAuthTransferMessage sign the authorized transfer message with the wallet signer
+func (w *Wallet) SignAuthTransferMessage(to, contractAddress, tokenID string, expiry time.Time) (string, error) {
+	m, err := authTransferMessage(to, contractAddress, tokenID, expiry)
 	if err != nil {
-		return "", ErrSignFailed
+		return "", err
 	}
-	return sig.Generic(), nil
+	return w.signMessage(m)
 }
 
-// SignAuthTransferMessage sign the authorized transfer message from privateKey
-func (w *Wallet) SignAuthTransferMessage(to, contractAddress, tokenID string, expiry time.Time) (string, error) {
+// authTransferMessage returns the authorized transfer message of a token
+func authTransferMessage(to, contractAddress, tokenID string, expiry time.Time) ([]byte, error) {
 	// timestamp
 	ts := big.NewInt(expiry.Unix())
 
 	// address
 	ad, err := tezos.ParseAddress(to)
 	if err != nil {
-		return "", ErrInvalidAddress
+		return nil, ErrInvalidAddress
 	}
 
 	contractAddr, err := tezos.ParseAddress(contractAddress)
 	if err != nil {
-		return "", ErrInvalidAddress
+		return nil, ErrInvalidAddress
 	}
 
 	// token
 	tk, ok := new(big.Int).SetString(tokenID, 10)
 	if !ok {
-		return "", ErrInvalidTokenID
+		return nil, ErrInvalidTokenID
 	}
 
 	tsp := micheline.Prim{
@@ -167,12 +332,21 @@ func (w *Wallet) SignAuthTransferMessage(to, contractAddress, tokenID string, ex
 		Int:  tk,
 	}
 
-	m := append(append(append(tsp.Pack(), ctp.Pack()...), adp.Pack()...), tkp.Pack()...)
-	return w.signMessage(m)
+	return append(append(append(tsp.Pack(), ctp.Pack()...), adp.Pack()...), tkp.Pack()...), nil
 }
 
 // Send will send a op to tezos blockchain and return hash
 func (w *Wallet) Send(args contract.CallArguments) (*string, error) {
+	res, err := w.SendWithResult(args)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Hash, nil
+}
+
+// SendWithResult sends a op to tezos blockchain like Send and returns the result
+// with the simulated costs
+func (w *Wallet) SendWithResult(args contract.CallArguments) (*CallResult, error) {
 	opts := &rpc.CallOptions{
 		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
 		MaxFee: 10_000_000,
@@ -181,17 +355,19 @@ func (w *Wallet) Send(args contract.CallArguments) (*string, error) {
 	op := codec.NewOp().WithTTL(opts.TTL)
 	op.WithContents(args.Encode())
 
-	if w.chainID.Equal(tezos.GhostnetParams.ChainId) {
-		op.WithParams(tezos.GhostnetParams)
-	} else {
-		op.WithParams(tezos.DefaultParams)
-	}
+	op.WithParams(w.params())
 
-	return w.send(op, opts)
+	return w.sendResult(op, opts, nil)
 }
 
 // SendOperations will send list of operations to tezos blockchain and return hash
 func (w *Wallet) SendOperations(ops []codec.Operation) (*string, error) {
+	op, opts := w.operationsOp(ops)
+	return w.send(op, opts)
+}
+
+// operationsOp constructs an operation from a list of operations
+func (w *Wallet) operationsOp(ops []codec.Operation) (*codec.Op, *rpc.CallOptions) {
 	opts := &rpc.CallOptions{
 		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
 		MaxFee: 10_000_000,
@@ -202,16 +378,16 @@ func (w *Wallet) SendOperations(ops []codec.Operation) (*string, error) {
 		op.WithContents(o)
 	}
 
-	if w.chainID.Equal(tezos.GhostnetParams.ChainId) {
-		op.WithParams(tezos.GhostnetParams)
-	} else {
-		op.WithParams(tezos.DefaultParams)
-	}
+	op.WithParams(w.params())
 
-	return w.send(op, opts)
+	return op, opts
 }
 
 func (w *Wallet) SimulateXTZTransferFee(txs []TransferXTZParam) (*int64, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
 	opts := &rpc.CallOptions{
 		TTL: tezos.DefaultParams.MaxOperationsTTL - 2,
 	}
@@ -227,19 +403,13 @@ func (w *Wallet) SimulateXTZTransferFee(txs []TransferXTZParam) (*int64, error)
 
 	ctx := context.Background()
 
-	signer := w.rpcClient.Signer
-
 	// identify the sender address for signing the message
 	addr := opts.Sender
 	if !addr.IsValid() {
-		addrs, err := signer.ListAddresses(ctx)
-		if err != nil {
-			return nil, err
-		}
-		addr = addrs[0]
+		addr = w.address
 	}
 
-	key, err := signer.GetKey(ctx, addr)
+	key, err := w.signer.GetKey(ctx, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -303,12 +473,32 @@ func (w *Wallet) SimulateXTZTransferFee(txs []TransferXTZParam) (*int64, error)
 // ensures minimum fees are set, protects against fee overpayment, signs and broadcasts the final
 // operation.
 func (w *Wallet) send(op *codec.Op, opts *rpc.CallOptions) (*string, error) {
+	return w.sendChecked(op, opts, nil)
+}
+
+// sendChecked sends an operation like send. The check, when given, is called
+// with the simulation receipt and stops the operation before signing when it
+// returns an error.
+func (w *Wallet) sendChecked(op *codec.Op, opts *rpc.CallOptions, check func(*rpc.Receipt) error) (*string, error) {
+	res, err := w.sendResult(op, opts, check)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Hash, nil
+}
+
+// sendResult sends an operation like sendChecked and returns its result
+func (w *Wallet) sendResult(op *codec.Op, opts *rpc.CallOptions, check func(*rpc.Receipt) error) (*CallResult, error) {
+	if w.IsOffline() {
+		return nil, ErrOfflineWallet
+	}
+
 	ctx := context.Background()
 	if opts == nil {
 		opts = &rpc.DefaultOptions
 	}
 
-	signer := w.rpcClient.Signer
+	var signer signer.Signer = w.signer
 	if opts.Signer != nil {
 		signer = opts.Signer
 	}
@@ -316,13 +506,68 @@ func (w *Wallet) send(op *codec.Op, opts *rpc.CallOptions) (*string, error) {
 	// identify the sender address for signing the message
 	addr := opts.Sender
 	if !addr.IsValid() {
-		addrs, err := signer.ListAddresses(ctx)
-		if err != nil {
+		addr = w.address
+	}
+
+	sim, err := w.prepare(ctx, op, opts, signer, addr)
+	if err != nil {
+		return nil, err
+	}
+	if check != nil {
+		if err := check(sim); err != nil {
 			return nil, err
 		}
-		addr = addrs[0]
 	}
 
+	// sign digest
+	sig, err := signer.SignOperation(ctx, addr, op)
+	if err != nil {
+		return nil, err
+	}
+	op.WithSignature(sig)
+
+	// broadcast
+	hash, err := w.rpcClient.Broadcast(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	res := newCallResult(hash.String(), op, sim)
+	if w.recorder != nil {
+		w.recorder.record(*res)
+	}
+	return res, nil
+}
+
+// sendAndWait sends an operation like sendChecked and waits until it is included in
+// a block. It returns the receipt of the included operation.
+func (w *Wallet) sendAndWait(op *codec.Op, opts *rpc.CallOptions, check func(*rpc.Receipt) error) (*rpc.Receipt, error) {
+	h, err := w.sendChecked(op, opts, check)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := tezos.ParseOpHash(*h)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	res := rpc.NewResult(hash).WithTTL(op.TTL).WithConfirmations(1)
+
+	// ensure block observer is running
+	mon := w.rpcClient.BlockObserver
+	mon.Listen(w.rpcClient)
+
+	res.Listen(mon)
+	res.WaitContext(ctx)
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res.GetReceipt(ctx)
+}
+
+// prepare auto-completes an operation, simulates it and applies the simulated
+// gas and storage limit. It returns the simulation receipt.
+func (w *Wallet) prepare(ctx context.Context, op *codec.Op, opts *rpc.CallOptions, signer signer.Signer, addr tezos.Address) (*rpc.Receipt, error) {
 	key, err := signer.GetKey(ctx, addr)
 	if err != nil {
 		return nil, err
@@ -360,30 +605,56 @@ func (w *Wallet) send(op *codec.Op, opts *rpc.CallOptions) (*string, error) {
 		}
 	}
 
-	// sign digest
-	sig, err := signer.SignOperation(ctx, addr, op)
-	if err != nil {
-		return nil, err
-	}
-	op.WithSignature(sig)
-
-	// broadcast
-	hash, err := w.rpcClient.Broadcast(ctx, op)
-	if err != nil {
-		return nil, err
-	}
-	h := hash.String()
-	return &h, nil
+	return sim, nil
 }
 
-// RPCClient returns the Tezos RPC client which is bound to the wallet
+// RPCClient returns the Tezos RPC client which is bound to the wallet.
+// It is nil for an offline wallet.
 func (w *Wallet) RPCClient() *rpc.Client {
 	return w.rpcClient
 }
 
+// IsOffline returns whether the wallet is created without a rpc node
+func (w *Wallet) IsOffline() bool {
+	return w.rpcClient == nil
+}
+
+// params returns the protocol params of the wallet chain
+func (w *Wallet) params() *tezos.Params {
+	if w.chainID.Equal(tezos.GhostnetParams.ChainId) {
+		return tezos.GhostnetParams
+	}
+	return tezos.DefaultParams
+}
+
 // Account returns the tezos account address string
 func (w *Wallet) Account() string {
-	return w.privateKey.Address().String()
+	return w.address.String()
+}
+
+// AccountIndex returns the derivation index of the wallet account
+func (w *Wallet) AccountIndex() uint {
+	return w.accountIndex
+}
+
+// KeyType returns the key type of the wallet account
+func (w *Wallet) KeyType() tezos.KeyType {
+	return w.keyType
+}
+
+// DerivationScheme returns the derivation path scheme of the wallet accounts
+func (w *Wallet) DerivationScheme() DerivationScheme {
+	return w.scheme
+}
+
+// Address returns the tezos account address
+func (w *Wallet) Address() tezos.Address {
+	return w.address
+}
+
+// Signer returns the signer backend which is bound to the wallet
+func (w *Wallet) Signer() Signer {
+	return w.signer
 }
 
 // ChainID returns the tezos wallet ChainID
@@ -391,9 +662,12 @@ func (w *Wallet) ChainID() string {
 	return w.chainID.String()
 }
 
-// Account returns the private key
+// PrivateKey returns the private key. It is empty when the wallet signs
+// through an external signer backend, the key export is disabled or the
+// wallet is wiped.
 func (w *Wallet) PrivateKey() tezos.PrivateKey {
-	return w.privateKey
+	key, _ := w.ExportPrivateKey()
+	return key
 }
 
 // TransferXTZ transfer the xtz to destination
@@ -410,6 +684,25 @@ func (w *Wallet) TransferXTZ(to string, amount int64) (*string, error) {
 
 // BatchTransferXTZ transfer the xtz to destinations
 func (w *Wallet) BatchTransferXTZ(txs []TransferXTZParam) (*string, error) {
+	res, err := w.BatchTransferXTZWithResult(txs)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Hash, nil
+}
+
+// BatchTransferXTZWithResult transfer the xtz to destinations like BatchTransferXTZ
+// and returns the result with the simulated costs
+func (w *Wallet) BatchTransferXTZWithResult(txs []TransferXTZParam) (*CallResult, error) {
+	op, opts, err := batchTransferXTZOp(txs)
+	if err != nil {
+		return nil, err
+	}
+	return w.sendResult(op, opts, nil)
+}
+
+// batchTransferXTZOp constructs an operation which transfers the xtz to destinations
+func batchTransferXTZOp(txs []TransferXTZParam) (*codec.Op, *rpc.CallOptions, error) {
 	opts := &rpc.CallOptions{
 		TTL:    tezos.DefaultParams.MaxOperationsTTL - 2,
 		MaxFee: 1_000_000,
@@ -418,13 +711,13 @@ func (w *Wallet) BatchTransferXTZ(txs []TransferXTZParam) (*string, error) {
 	for _, tx := range txs {
 		ad, err := tezos.ParseAddress(tx.To)
 		if err != nil {
-			return nil, ErrInvalidAddress
+			return nil, nil, ErrInvalidAddress
 		}
 		// construct a transfer operation
 		op.WithTransfer(ad, tx.Amount)
 	}
 
-	return w.send(op, opts)
+	return op, opts, nil
 }
 
 // convert an ed25519 hd private key to tzgo private key
@@ -432,6 +725,6 @@ func toTzgoPrivateKey(edk ed25519hd.PrivateKey) tezos.PrivateKey {
 	key := tezos.PrivateKey{
 		Type: tezos.KeyTypeEd25519,
 	}
-	key.Data = append(edk.Key, edk.GetPublicKey()...)
+	key.Data = append(append([]byte{}, edk.Key...), edk.GetPublicKey()...)
 	return key
 }